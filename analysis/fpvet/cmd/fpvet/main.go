@@ -0,0 +1,18 @@
+// Command fpvet checks the signatures of functions passed to fp. It lives in
+// the module fp/analysis/fpvet, so that fp doesn't depend on x/tools. Build it
+// there and run it on packages or as a vet tool:
+//
+//	(cd analysis/fpvet && go build -o ../../fpvet ./cmd/fpvet)
+//	./fpvet ./...
+//	go vet -vettool=$(pwd)/fpvet ./...
+package main
+
+import (
+	"fp/analysis/fpvet"
+
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(fpvet.Analyzer)
+}
@@ -0,0 +1,365 @@
+// Package fpvet defines an Analyzer that checks the functions passed to fp
+// against the element types of the slices they are applied to, the mistakes
+// mustBeFuncSignature would otherwise only report at run time.
+package fpvet
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const Doc = `check the signatures of functions passed to fp
+
+fpvet reports calls of fp.Map, Filter, Fold, MapIndexed, MapThread, Do,
+Sort, DeleteDuplicates and Intersection whose function doesn't have the
+signature required by the element type of the slices, e.g. a func(string) int
+mapped over []int, a Fold whose accumulator doesn't match its initial value
+or a Sort comparator which doesn't give a bool. Arguments whose static type
+is an interface are only known at run time and aren't checked.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "fpvet",
+	Doc:      Doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// fpPath is the import path of the fp package.
+var fpPath = "fp"
+
+func init() {
+	Analyzer.Flags.StringVar(&fpPath, "fp", fpPath, "import path of the fp package")
+}
+
+// checkers validate the arguments of a call of the fp function of the same name.
+var checkers = map[string]func(c *call){
+	"Map":              checkMap,
+	"Filter":           checkFilter,
+	"Fold":             checkFold,
+	"MapIndexed":       checkMapIndexed,
+	"MapThread":        checkMapThread,
+	"Do":               checkDo,
+	"Sort":             checkSort,
+	"DeleteDuplicates": checkDeleteDuplicates,
+	"Intersection":     checkIntersection,
+}
+
+var (
+	boolType  = types.Typ[types.Bool]
+	intType   = types.Typ[types.Int]
+	emptyType = types.NewInterfaceType(nil, nil).Complete()
+)
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	inspect.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		expr := n.(*ast.CallExpr)
+		fn := callee(pass.TypesInfo, expr)
+		if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != fpPath {
+			return
+		}
+		if check, ok := checkers[fn.Name()]; ok {
+			check(&call{pass: pass, expr: expr, name: fn.Name()})
+		}
+	})
+	return nil, nil
+}
+
+func callee(info *types.Info, expr *ast.CallExpr) *types.Func {
+	fun := expr.Fun
+	for {
+		paren, ok := fun.(*ast.ParenExpr)
+		if !ok {
+			break
+		}
+		fun = paren.X
+	}
+
+	var id *ast.Ident
+	switch fun := fun.(type) {
+	case *ast.Ident:
+		id = fun
+	case *ast.SelectorExpr:
+		id = fun.Sel
+	default:
+		return nil
+	}
+	fn, _ := info.Uses[id].(*types.Func)
+	return fn
+}
+
+type call struct {
+	pass *analysis.Pass
+	expr *ast.CallExpr
+	name string
+}
+
+func (c *call) args() []ast.Expr {
+	if c.expr.Ellipsis.IsValid() {
+		// The arguments of f(xs...) aren't known statically.
+		return nil
+	}
+	return c.expr.Args
+}
+
+// typeOf gives the static type of e, or nil when it's only known at run time.
+func (c *call) typeOf(e ast.Expr) types.Type {
+	t := c.pass.TypesInfo.TypeOf(e)
+	if t == nil || types.IsInterface(t) {
+		return nil
+	}
+	if b, ok := t.(*types.Basic); ok && b.Kind() == types.UntypedNil {
+		return nil
+	}
+	return t
+}
+
+// elem gives the element type of a slice or array, or nil for other
+// collections such as maps, structs and associations.
+func (c *call) elem(e ast.Expr) types.Type {
+	t := c.typeOf(e)
+	if t == nil {
+		return nil
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Slice:
+		return u.Elem()
+	case *types.Array:
+		return u.Elem()
+	}
+	return nil
+}
+
+// signature gives the signature of the function f, it reports f when it
+// isn't a function.
+func (c *call) signature(f ast.Expr) *types.Signature {
+	t := c.typeOf(f)
+	if t == nil {
+		return nil
+	}
+	sig, ok := t.Underlying().(*types.Signature)
+	if !ok {
+		c.pass.Reportf(f.Pos(), "fp.%v: %v should be a function but not %v", c.name, render(c.pass, f), t)
+	}
+	return sig
+}
+
+// expect reports f unless its signature has the parameters ins and the
+// results outs, a nil type matches any type. An interface parameter accepts
+// the types which implement it, as it does at run time.
+func (c *call) expect(f ast.Expr, ins []types.Type, outs []types.Type) {
+	sig := c.signature(f)
+	if sig == nil || matches(sig, ins, outs) {
+		return
+	}
+	expected := "func(" + typeList(c.pass, ins, "T") + ")"
+	switch len(outs) {
+	case 0:
+	case 1:
+		expected += " " + typeList(c.pass, outs, "R")
+	default:
+		expected += " (" + typeList(c.pass, outs, "R") + ")"
+	}
+	c.pass.Reportf(f.Pos(), "fp.%v: %v should be %v but not %v", c.name, render(c.pass, f), expected, types.TypeString(sig, types.RelativeTo(c.pass.Pkg)))
+}
+
+func matches(sig *types.Signature, ins []types.Type, outs []types.Type) bool {
+	if sig.Variadic() || sig.Params().Len() != len(ins) || sig.Results().Len() != len(outs) {
+		return false
+	}
+	for i, t := range ins {
+		in := sig.Params().At(i).Type()
+		if t != nil && !types.Identical(in, t) && !(types.IsInterface(in) && types.AssignableTo(t, in)) {
+			return false
+		}
+	}
+	for i, t := range outs {
+		if t != nil && !types.Identical(sig.Results().At(i).Type(), t) {
+			return false
+		}
+	}
+	return true
+}
+
+func typeList(pass *analysis.Pass, ts []types.Type, unknown string) string {
+	names := make([]string, len(ts))
+	for i, t := range ts {
+		if t == nil {
+			names[i] = unknown
+		} else {
+			names[i] = types.TypeString(t, types.RelativeTo(pass.Pkg))
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+func render(pass *analysis.Pass, e ast.Expr) string {
+	if _, ok := e.(*ast.FuncLit); ok {
+		return "the function literal"
+	}
+	return types.ExprString(e)
+}
+
+func checkMap(c *call) {
+	args := c.args()
+	// Level specifications apply f to nested parts, which aren't checked.
+	if len(args) != 2 {
+		return
+	}
+	if e := c.elem(args[1]); e != nil {
+		c.expect(args[0], []types.Type{e}, []types.Type{nil})
+	}
+}
+
+func checkFilter(c *call) {
+	args := c.args()
+	if len(args) != 2 {
+		return
+	}
+	if e := c.elem(args[1]); e != nil {
+		c.expect(args[0], []types.Type{e}, []types.Type{boolType})
+	}
+}
+
+func checkFold(c *call) {
+	args := c.args()
+	if len(args) != 3 {
+		return
+	}
+	e := c.elem(args[2])
+	r := c.typeOf(args[1])
+	if e == nil || r == nil {
+		return
+	}
+	// f may give any result it takes back as its accumulator, e.g. Fold(Plus, 0, xs).
+	sig := c.signature(args[0])
+	if sig == nil || matches(sig, []types.Type{r, e}, []types.Type{nil}) && types.AssignableTo(sig.Results().At(0).Type(), sig.Params().At(0).Type()) {
+		return
+	}
+	c.expect(args[0], []types.Type{r, e}, []types.Type{r})
+}
+
+func checkMapIndexed(c *call) {
+	args := c.args()
+	if len(args) != 2 {
+		return
+	}
+	if e := c.elem(args[1]); e != nil {
+		c.expect(args[0], []types.Type{e, intType}, []types.Type{nil})
+	}
+}
+
+func checkMapThread(c *call) {
+	args := c.args()
+	if len(args) < 2 {
+		return
+	}
+	ins := []types.Type{}
+	for _, arg := range args[1:] {
+		e := c.elem(arg)
+		if e == nil {
+			return
+		}
+		ins = append(ins, e)
+	}
+	c.expect(args[0], ins, []types.Type{nil})
+}
+
+func checkDo(c *call) {
+	args := c.args()
+	if len(args) != 2 {
+		return
+	}
+	if e := c.elem(args[1]); e != nil {
+		c.expect(args[0], []types.Type{e}, nil)
+	}
+}
+
+func checkSort(c *call) {
+	args := c.args()
+	switch len(args) {
+	case 1:
+		e := c.elem(args[0])
+		if e != nil && !ordered(e) {
+			c.pass.Reportf(args[0].Pos(), "fp.%v: %v has no canonical order; use Sort(xs, less)", c.name, types.TypeString(e, types.RelativeTo(c.pass.Pkg)))
+		}
+	case 2:
+		// Sort passes the elements to less as interface{} values.
+		if c.elem(args[0]) != nil {
+			c.expect(args[1], []types.Type{emptyType, emptyType}, []types.Type{boolType})
+		}
+	}
+}
+
+// ordered reports whether Sort can order elements of type t without less,
+// interfaces are only known at run time.
+func ordered(t types.Type) bool {
+	if types.IsInterface(t) || bigNumber(t) {
+		return true
+	}
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&(types.IsInteger|types.IsFloat|types.IsString) != 0
+}
+
+// bigNumber reports whether t is *big.Int, *big.Rat or *big.Float of math/big.
+func bigNumber(t types.Type) bool {
+	p, ok := t.(*types.Pointer)
+	if !ok {
+		return false
+	}
+	named, ok := p.Elem().(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || named.Obj().Pkg().Path() != "math/big" {
+		return false
+	}
+	switch named.Obj().Name() {
+	case "Int", "Rat", "Float":
+		return true
+	}
+	return false
+}
+
+func checkDeleteDuplicates(c *call) {
+	args := c.args()
+	if len(args) != 2 {
+		return
+	}
+	if e := c.elem(args[0]); e != nil {
+		c.expect(args[1], []types.Type{e, e}, []types.Type{boolType})
+	}
+}
+
+func checkIntersection(c *call) {
+	lists := c.args()
+	if len(lists) == 0 {
+		return
+	}
+	var f ast.Expr
+	if t := c.typeOf(lists[len(lists)-1]); t != nil {
+		if _, ok := t.Underlying().(*types.Signature); ok {
+			f = lists[len(lists)-1]
+			lists = lists[:len(lists)-1]
+		}
+	}
+	if len(lists) == 0 {
+		return
+	}
+
+	e := c.elem(lists[0])
+	if e == nil {
+		return
+	}
+	for _, list := range lists[1:] {
+		if other := c.elem(list); other != nil && !types.Identical(other, e) {
+			c.pass.Reportf(list.Pos(), "fp.%v: %v should have elements of type %v but not %v", c.name, types.ExprString(list),
+				types.TypeString(e, types.RelativeTo(c.pass.Pkg)), types.TypeString(other, types.RelativeTo(c.pass.Pkg)))
+		}
+	}
+	if f != nil {
+		c.expect(f, []types.Type{e, e}, []types.Type{boolType})
+	}
+}
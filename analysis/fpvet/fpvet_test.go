@@ -0,0 +1,13 @@
+package fpvet_test
+
+import (
+	"testing"
+
+	"fp/analysis/fpvet"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), fpvet.Analyzer, "a")
+}
@@ -0,0 +1,63 @@
+package a
+
+import (
+	"fp"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+type Person struct {
+	Name string
+	Age  int
+}
+
+func square(x int) int { return x * x }
+
+func sum(r, x int) int { return r + x }
+
+func Valid(xs []int, ys [3]int, names []string, people []Person, any interface{}) {
+	fp.Map(square, xs)
+	fp.Map(strconv.Itoa, ys)
+	fp.Map(square, any)
+	fp.Map(any, xs)
+	fp.Map(strings.ToUpper, map[string]string{})
+	fp.Map(square, xs, 2)
+	fp.Filter(func(x int) bool { return x > 0 }, xs)
+	fp.Fold(sum, 0, xs)
+	fp.Fold(func(r string, x int) string { return r + strconv.Itoa(x) }, "", xs)
+	fp.Fold(fp.Plus, 0, xs)
+	fp.Filter(func(x interface{}) bool { return x != nil }, names)
+	fp.MapIndexed(func(x int, i int) string { return "" }, xs)
+	fp.MapThread(func(x int, s string) Person { return Person{s, x} }, xs, names)
+	fp.Do(func(p Person) {}, people)
+	fp.Sort(xs)
+	fp.Sort(names)
+	fp.Sort([]interface{}{1, 2})
+	fp.Sort([]*big.Int{big.NewInt(2), big.NewInt(1)})
+	fp.Sort(people, func(a, b interface{}) bool { return a.(Person).Age < b.(Person).Age })
+	fp.Sort(xs, fp.Less)
+	fp.DeleteDuplicates(xs, func(a, b int) bool { return a == b })
+	fp.Intersection(xs, xs, func(a, b int) bool { return a == b })
+	fp.Intersection(names, names)
+}
+
+func Invalid(xs []int, names []string, people []Person) {
+	fp.Map(strings.ToUpper, xs)                                       // want `fp.Map: strings.ToUpper should be func\(int\) R but not func\(s string\) string`
+	fp.Map(func(x, y int) int { return x }, xs)                       // want `fp.Map: the function literal should be func\(int\) R but not func\(x int, y int\) int`
+	fp.Map(42, xs)                                                    // want `fp.Map: 42 should be a function but not int`
+	fp.Filter(square, xs)                                             // want `fp.Filter: square should be func\(int\) bool but not func\(x int\) int`
+	fp.Fold(sum, "", xs)                                              // want `fp.Fold: sum should be func\(string, int\) string but not func\(r int, x int\) int`
+	fp.Fold(sum, 0.0, xs)                                             // want `fp.Fold: sum should be func\(float64, int\) float64`
+	fp.Fold(func(r, x int) interface{} { return r }, 0, xs)           // want `fp.Fold: the function literal should be func\(int, int\) int`
+	fp.Filter(func(x interface{}) interface{} { return x }, xs)       // want `fp.Filter: the function literal should be func\(int\) bool`
+	fp.MapIndexed(square, xs)                                         // want `fp.MapIndexed: square should be func\(int, int\) R`
+	fp.MapThread(func(x, y int) int { return x }, xs, names)          // want `fp.MapThread: the function literal should be func\(int, string\) R`
+	fp.Do(square, xs)                                                 // want `fp.Do: square should be func\(int\) but not func\(x int\) int`
+	fp.Sort(people)                                                   // want `fp.Sort: Person has no canonical order`
+	fp.Sort(xs, func(a, b interface{}) int { return 0 })              // want `fp.Sort: the function literal should be func\(interface\{\}, interface\{\}\) bool`
+	fp.Sort(xs, func(a, b int) bool { return a < b })                 // want `fp.Sort: the function literal should be func\(interface\{\}, interface\{\}\) bool`
+	fp.DeleteDuplicates(names, func(a, b int) bool { return a == b }) // want `fp.DeleteDuplicates: the function literal should be func\(string, string\) bool`
+	fp.Intersection(xs, names)                                        // want `fp.Intersection: names should have elements of type int but not string`
+	fp.Intersection(xs, xs, func(a, b int) int { return 0 })          // want `fp.Intersection: the function literal should be func\(int, int\) bool`
+}
@@ -0,0 +1,26 @@
+// Package fp declares the functions fpvet checks, with the signatures of the real package.
+package fp
+
+func Map(f interface{}, slice interface{}, levelspec ...interface{}) interface{} { return nil }
+
+func Filter(f interface{}, slice interface{}) interface{} { return nil }
+
+func Fold(f interface{}, initial interface{}, slice interface{}) interface{} { return nil }
+
+func MapIndexed(f interface{}, slice interface{}) interface{} { return nil }
+
+func MapThread(f interface{}, slices ...interface{}) interface{} { return nil }
+
+func Do(f interface{}, slice interface{}) {}
+
+func Sort(args ...interface{}) interface{} { return nil }
+
+func Less(a interface{}, b interface{}) bool { return false }
+
+func DeleteDuplicates(args ...interface{}) interface{} { return nil }
+
+func Intersection(args ...interface{}) interface{} { return nil }
+
+func Plus(x interface{}, y interface{}) interface{} { return nil }
+
+type Association struct{}
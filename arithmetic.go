@@ -0,0 +1,458 @@
+package fp
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/cmplx"
+	"reflect"
+)
+
+// The arithmetic functions accept every numeric kind and the big numbers, and
+// they are Listable: they thread elementwise over slices and nested arrays,
+// e.g. Plus([]int{1, 2}, 10) is []int{11, 12}. They take and give interface{}
+// values, so they work as combinators, e.g. Fold(Plus, 0, xs).
+//
+// Mixed operands are promoted to the more general of
+//
+//	integers < *big.Int < *big.Rat < *big.Float < floats < complex numbers
+//
+// Integers, *big.Int and *big.Rat are exact: an integer result keeps the type
+// of x unless it overflows and is promoted to *big.Int. A *big.Float has the
+// larger precision of its operands. Floats and complex numbers are single
+// precision only when no operand is float64 or complex128.
+//
+// The element type of a result list follows from the element types of the
+// operands, not from their values, so the quotients of integers in a list are
+// all *big.Rat. Only when an integer element overflows does the list become a
+// list of *big.Int as a whole, e.g. Plus([]int{math.MaxInt64, 1}, 1) is a
+// []*big.Int, and so does a list of Pow of integers or *big.Int become a list
+// of *big.Rat when an exponent is negative. Lists of interface{} give lists of interface{}.
+
+// Plus gives x + y.
+func Plus(x interface{}, y interface{}) interface{} {
+	return arithmetic("Plus", x, y, plus)
+}
+
+// Subtract gives x - y.
+func Subtract(x interface{}, y interface{}) interface{} {
+	return arithmetic("Subtract", x, y, subtract)
+}
+
+// Times gives x * y.
+func Times(x interface{}, y interface{}) interface{} {
+	return arithmetic("Times", x, y, times)
+}
+
+// Divide gives x / y. The quotient of integers is an integer when y divides x,
+// and a *big.Rat otherwise. In lists they are all *big.Rat.
+func Divide(x interface{}, y interface{}) interface{} {
+	return arithmetic("Divide", x, y, divide)
+}
+
+// Mod gives the remainder of x / y, which has the sign of y.
+func Mod(x interface{}, y interface{}) interface{} {
+	return arithmetic("Mod", x, y, mod)
+}
+
+// Quotient gives Floor(x / y), an integer for exact numbers.
+func Quotient(x interface{}, y interface{}) interface{} {
+	return arithmetic("Quotient", x, y, quotient)
+}
+
+// Sign gives -1, 0 or 1 as an int for a real x, and x / Abs(x) for a complex x.
+func Sign(x interface{}) interface{} {
+	return listable("Sign", func(args []interface{}) interface{} {
+		return sign("Sign", args[0])
+	}, func(types []reflect.Type) reflect.Type {
+		tier, ok := tierOfType(types[0])
+		switch {
+		case !ok:
+			return interfaceType
+		case tier == complexTier:
+			return types[0]
+		}
+		return reflect.TypeOf(0)
+	}, x)
+}
+
+// Round gives the integer nearest to x, halves round to even. Integers, big
+// floats, floats and complex numbers keep their type, *big.Rat gives *big.Int.
+func Round(x interface{}) interface{} {
+	return rounding("Round", x, math.RoundToEven, func(r *big.Rat) *big.Int {
+		twice := new(big.Rat).Add(r, r)
+		z := floorRat(new(big.Rat).Add(r, big.NewRat(1, 2)))
+		// Halves, whose double is odd, go to the even neighbour.
+		if twice.IsInt() && twice.Num().Bit(0) == 1 && z.Bit(0) == 1 {
+			z.Sub(z, bigOne)
+		}
+		return z
+	})
+}
+
+// Floor gives the greatest integer less than or equal to x, see Round.
+func Floor(x interface{}) interface{} {
+	return rounding("Floor", x, math.Floor, floorRat)
+}
+
+// Ceiling gives the least integer greater than or equal to x, see Round.
+func Ceiling(x interface{}) interface{} {
+	return rounding("Ceiling", x, math.Ceil, func(r *big.Rat) *big.Int {
+		z := floorRat(new(big.Rat).Neg(r))
+		return z.Neg(z)
+	})
+}
+
+// operator implements a binary arithmetic function for each tier of numbers,
+// a nil implementation doesn't support the tier.
+type operator struct {
+	integer  func(a, b int64) (int64, bool)
+	exact    func(a, b *big.Rat) *big.Rat
+	bigFloat func(a, b *big.Float) *big.Float
+	float    func(a, b float64) float64
+	complex  func(a, b complex128) complex128
+	// divides panics on an exact zero y.
+	divides bool
+	// rational gives *big.Rat for integer operands in lists.
+	rational bool
+	// integral gives integers for all exact operands.
+	integral bool
+}
+
+var plus = operator{
+	integer: func(a, b int64) (int64, bool) {
+		s := a + b
+		return s, (a^s)&(b^s) >= 0
+	},
+	exact:    func(a, b *big.Rat) *big.Rat { return a.Add(a, b) },
+	bigFloat: func(a, b *big.Float) *big.Float { return a.Add(a, b) },
+	float:    func(a, b float64) float64 { return a + b },
+	complex:  func(a, b complex128) complex128 { return a + b },
+}
+
+var subtract = operator{
+	integer: func(a, b int64) (int64, bool) {
+		d := a - b
+		return d, (a^b)&(a^d) >= 0
+	},
+	exact:    func(a, b *big.Rat) *big.Rat { return a.Sub(a, b) },
+	bigFloat: func(a, b *big.Float) *big.Float { return a.Sub(a, b) },
+	float:    func(a, b float64) float64 { return a - b },
+	complex:  func(a, b complex128) complex128 { return a - b },
+}
+
+var times = operator{
+	integer: func(a, b int64) (int64, bool) {
+		if a == 0 || b == 0 {
+			return 0, true
+		}
+		p := a * b
+		return p, p/b == a && !(a == -1 && b == math.MinInt64) && !(b == -1 && a == math.MinInt64)
+	},
+	exact:    func(a, b *big.Rat) *big.Rat { return a.Mul(a, b) },
+	bigFloat: func(a, b *big.Float) *big.Float { return a.Mul(a, b) },
+	float:    func(a, b float64) float64 { return a * b },
+	complex:  func(a, b complex128) complex128 { return a * b },
+}
+
+var divide = operator{
+	exact:    func(a, b *big.Rat) *big.Rat { return a.Quo(a, b) },
+	bigFloat: func(a, b *big.Float) *big.Float { return a.Quo(a, b) },
+	float:    func(a, b float64) float64 { return a / b },
+	complex:  func(a, b complex128) complex128 { return a / b },
+	divides:  true,
+	rational: true,
+}
+
+var mod = operator{
+	exact: func(a, b *big.Rat) *big.Rat {
+		q := new(big.Rat).SetInt(floorRat(new(big.Rat).Quo(a, b)))
+		return a.Sub(a, q.Mul(q, b))
+	},
+	float: func(a, b float64) float64 {
+		r := math.Mod(a, b)
+		if r != 0 && (r < 0) != (b < 0) {
+			r += b
+		}
+		return r
+	},
+	divides: true,
+}
+
+var quotient = operator{
+	exact: func(a, b *big.Rat) *big.Rat {
+		return a.SetInt(floorRat(a.Quo(a, b)))
+	},
+	float:    func(a, b float64) float64 { return math.Floor(a / b) },
+	divides:  true,
+	integral: true,
+}
+
+// The tiers of numbers in the order of promotion.
+const (
+	integerTier = iota
+	bigIntTier
+	bigRatTier
+	bigFloatTier
+	floatTier
+	complexTier
+)
+
+func tierOf(name string, x interface{}) int {
+	tier, ok := tierOfType(reflect.TypeOf(x))
+	if !ok {
+		msg := fmt.Sprintf("%v: %v should be a number.", name, x)
+		panic(msg)
+	}
+	return tier
+}
+
+// tierOfType gives the tier of the numbers of type t, ok is false when t isn't
+// a number type.
+func tierOfType(t reflect.Type) (tier int, ok bool) {
+	switch t {
+	case nil:
+		return 0, false
+	case bigIntType:
+		return bigIntTier, true
+	case bigRatType:
+		return bigRatTier, true
+	case bigFloatType:
+		return bigFloatTier, true
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return integerTier, true
+	case reflect.Float32, reflect.Float64:
+		return floatTier, true
+	case reflect.Complex64, reflect.Complex128:
+		return complexTier, true
+	}
+	return 0, false
+}
+
+// resultType gives the type of op on numbers of the types x and y, which the
+// results of op in a list are converted to. It is interface{} unless both are
+// number types.
+func (op operator) resultType(x reflect.Type, y reflect.Type) reflect.Type {
+	tierX, okX := tierOfType(x)
+	tierY, okY := tierOfType(y)
+	if !okX || !okY {
+		return interfaceType
+	}
+	tier := tierX
+	if tierY > tier {
+		tier = tierY
+	}
+	switch tier {
+	case integerTier, bigIntTier:
+		if op.rational {
+			return bigRatType
+		}
+		if tier == integerTier {
+			return x
+		}
+		return bigIntType
+	case bigRatTier:
+		if op.integral {
+			return bigIntType
+		}
+		return bigRatType
+	case bigFloatTier:
+		return bigFloatType
+	case floatTier:
+		if doubleType(x) || doubleType(y) {
+			return reflect.TypeOf(float64(0))
+		}
+		return reflect.TypeOf(float32(0))
+	default:
+		if doubleType(x) || doubleType(y) {
+			return reflect.TypeOf(complex128(0))
+		}
+		return reflect.TypeOf(complex64(0))
+	}
+}
+
+func arithmetic(name string, x interface{}, y interface{}, op operator) interface{} {
+	return listable(name, func(args []interface{}) interface{} {
+		return scalarArithmetic(name, args[0], args[1], op)
+	}, func(types []reflect.Type) reflect.Type {
+		return op.resultType(types[0], types[1])
+	}, x, y)
+}
+
+func scalarArithmetic(name string, x interface{}, y interface{}, op operator) interface{} {
+	tier := tierOf(name, x)
+	if t := tierOf(name, y); t > tier {
+		tier = t
+	}
+	if op.divides && tier <= bigFloatTier && compareNumbers(name, y, 0) == 0 {
+		msg := fmt.Sprintf("%v: %v / %v is a division by zero.", name, x, y)
+		panic(msg)
+	}
+
+	switch tier {
+	case integerTier, bigIntTier, bigRatTier:
+		return exactArithmetic(name, x, y, op)
+	case bigFloatTier:
+		if op.bigFloat == nil {
+			return exactArithmetic(name, x, y, op)
+		}
+		prec := bigFloatPrec(x)
+		if p := bigFloatPrec(y); p > prec {
+			prec = p
+		}
+		return op.bigFloat(toBigFloat(x, prec), toBigFloat(y, prec))
+	case floatTier:
+		f := op.float(realValue(name, x), realValue(name, y))
+		if !double(x) && !double(y) {
+			return float32(f)
+		}
+		return f
+	default:
+		if op.complex == nil {
+			msg := fmt.Sprintf("%v: %v and %v should be real numbers.", name, x, y)
+			panic(msg)
+		}
+		z := op.complex(complexValue(name, x), complexValue(name, y))
+		if !double(x) && !double(y) {
+			return complex64(z)
+		}
+		return z
+	}
+}
+
+func exactArithmetic(name string, x interface{}, y interface{}, op operator) interface{} {
+	// Integers of the same signed type avoid big numbers until they overflow.
+	if op.integer != nil && reflect.TypeOf(x) == reflect.TypeOf(y) {
+		if v := reflect.ValueOf(x); v.Kind() >= reflect.Int && v.Kind() <= reflect.Int64 {
+			if n, ok := op.integer(v.Int(), reflect.ValueOf(y).Int()); ok && !v.OverflowInt(n) {
+				result := reflect.New(v.Type()).Elem()
+				result.SetInt(n)
+				return result.Interface()
+			}
+		}
+	}
+
+	a, infA := exactRat(name, x)
+	b, infB := exactRat(name, y)
+	if infA != 0 || infB != 0 {
+		msg := fmt.Sprintf("%v: %v and %v should be finite.", name, x, y)
+		panic(msg)
+	}
+	r := op.exact(new(big.Rat).Set(a), b)
+	if _, ok := x.(*big.Float); ok {
+		return new(big.Float).SetPrec(x.(*big.Float).Prec()).SetRat(r)
+	}
+	if _, ok := y.(*big.Float); ok {
+		return new(big.Float).SetPrec(y.(*big.Float).Prec()).SetRat(r)
+	}
+
+	_, ratX := x.(*big.Rat)
+	_, ratY := y.(*big.Rat)
+	if (ratX || ratY) && !op.integral || !r.IsInt() {
+		return r
+	}
+	z := new(big.Int).Set(r.Num())
+	if tierOf(name, x) > integerTier || tierOf(name, y) > integerTier {
+		return z
+	}
+	if v, ok := fitInteger(z, x); ok {
+		return v
+	}
+	return z
+}
+
+// double reports whether x makes a float or complex result double precision.
+func double(x interface{}) bool {
+	return doubleType(reflect.TypeOf(x))
+}
+
+func doubleType(t reflect.Type) bool {
+	return t != nil && (t.Kind() == reflect.Float64 || t.Kind() == reflect.Complex128)
+}
+
+func bigFloatPrec(x interface{}) uint {
+	if f, ok := x.(*big.Float); ok {
+		return f.Prec()
+	}
+	return 0
+}
+
+func toBigFloat(x interface{}, prec uint) *big.Float {
+	f := new(big.Float).SetPrec(prec)
+	switch x := x.(type) {
+	case *big.Int:
+		return f.SetInt(x)
+	case *big.Rat:
+		return f.SetRat(x)
+	case *big.Float:
+		return f.Set(x)
+	}
+	if isInteger(x) {
+		return f.SetInt(bigInteger("toBigFloat", x))
+	}
+	return f.SetFloat64(realValue("toBigFloat", x))
+}
+
+func complexValue(name string, x interface{}) complex128 {
+	v := reflect.ValueOf(x)
+	if v.Kind() == reflect.Complex64 || v.Kind() == reflect.Complex128 {
+		return v.Complex()
+	}
+	return complex(realValue(name, x), 0)
+}
+
+func sign(name string, x interface{}) interface{} {
+	switch v := reflect.ValueOf(x); v.Kind() {
+	case reflect.Complex64, reflect.Complex128:
+		z := v.Complex()
+		if z != 0 {
+			z /= complex(cmplx.Abs(z), 0)
+		}
+		return reflect.ValueOf(z).Convert(v.Type()).Interface()
+	case reflect.Float32, reflect.Float64:
+		if math.IsNaN(v.Float()) {
+			msg := fmt.Sprintf("%v: %v has no sign.", name, x)
+			panic(msg)
+		}
+	}
+	tierOf(name, x)
+	return compareNumbers(name, x, 0)
+}
+
+func rounding(name string, x interface{}, float func(float64) float64, exact func(*big.Rat) *big.Int) interface{} {
+	return listable(name, func(args []interface{}) interface{} {
+		x := args[0]
+		switch tierOf(name, x) {
+		case integerTier, bigIntTier:
+			return x
+		case bigRatTier:
+			return exact(x.(*big.Rat))
+		case bigFloatTier:
+			f := x.(*big.Float)
+			if f.IsInf() {
+				return f
+			}
+			r, _ := f.Rat(nil)
+			return new(big.Float).SetPrec(f.Prec()).SetInt(exact(r))
+		case floatTier:
+			v := reflect.ValueOf(x)
+			return reflect.ValueOf(float(v.Float())).Convert(v.Type()).Interface()
+		default:
+			v := reflect.ValueOf(x)
+			z := v.Complex()
+			return reflect.ValueOf(complex(float(real(z)), float(imag(z)))).Convert(v.Type()).Interface()
+		}
+	}, func(types []reflect.Type) reflect.Type {
+		tier, ok := tierOfType(types[0])
+		switch {
+		case !ok:
+			return interfaceType
+		case tier == bigRatTier:
+			return bigIntType
+		}
+		return types[0]
+	}, x)
+}
@@ -0,0 +1,289 @@
+package fp
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+type Rule struct {
+	Key   interface{}
+	Value interface{}
+}
+
+// Association is a map which remembers the order in which keys were inserted.
+type Association struct {
+	keys   []interface{}
+	values map[interface{}]interface{}
+}
+
+func NewAssociation(rules ...Rule) *Association {
+	a := &Association{values: map[interface{}]interface{}{}}
+	for _, rule := range rules {
+		a.Set(rule.Key, rule.Value)
+	}
+	return a
+}
+
+func (a *Association) Len() int {
+	return len(a.keys)
+}
+
+func (a *Association) Keys() []interface{} {
+	keys := make([]interface{}, len(a.keys))
+	copy(keys, a.keys)
+	return keys
+}
+
+func (a *Association) Values() []interface{} {
+	values := make([]interface{}, len(a.keys))
+	for i, key := range a.keys {
+		values[i] = a.values[key]
+	}
+	return values
+}
+
+func (a *Association) Rules() []Rule {
+	rules := make([]Rule, len(a.keys))
+	for i, key := range a.keys {
+		rules[i] = Rule{Key: key, Value: a.values[key]}
+	}
+	return rules
+}
+
+func (a *Association) Get(key interface{}) (interface{}, bool) {
+	mustBeHashable("Association", key)
+	value, ok := a.values[key]
+	return value, ok
+}
+
+func (a *Association) Set(key interface{}, value interface{}) {
+	mustBeHashable("Association", key)
+	if _, ok := a.values[key]; !ok {
+		a.keys = append(a.keys, key)
+	}
+	a.values[key] = value
+}
+
+func (a *Association) Delete(key interface{}) {
+	mustBeHashable("Association", key)
+	if _, ok := a.values[key]; !ok {
+		return
+	}
+	delete(a.values, key)
+	for i, k := range a.keys {
+		if k == key {
+			a.keys = append(a.keys[:i:i], a.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+func (a *Association) String() string {
+	buf := strings.Builder{}
+	buf.WriteString("<|")
+	for i, key := range a.keys {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(fmt.Sprintf("%v -> %v", key, a.values[key]))
+	}
+	buf.WriteString("|>")
+	return buf.String()
+}
+
+func mustBeHashable(name string, key interface{}) {
+	if key != nil && !reflect.TypeOf(key).Comparable() {
+		msg := fmt.Sprintf("%v: %v can't be used as a key.", name, key)
+		panic(msg)
+	}
+}
+
+func AssociationThread(keys interface{}, values interface{}) *Association {
+	kv := reflect.ValueOf(keys)
+	vv := reflect.ValueOf(values)
+	mustBeArraySlice(kv)
+	mustBeArraySlice(vv)
+	if kv.Len() != vv.Len() {
+		msg := fmt.Sprintf("AssociationThread: %v and %v should have the same length.", keys, values)
+		panic(msg)
+	}
+
+	a := NewAssociation()
+	for i := 0; i < kv.Len(); i++ {
+		a.Set(kv.Index(i).Interface(), vv.Index(i).Interface())
+	}
+	return a
+}
+
+func Lookup(a *Association, key interface{}, defaultValue ...interface{}) interface{} {
+	value, ok := a.Get(key)
+	if ok {
+		return value
+	}
+	if len(defaultValue) > 0 {
+		return defaultValue[0]
+	}
+	msg := fmt.Sprintf("Lookup: key %v is missing in %v.", key, a)
+	panic(msg)
+}
+
+// LookupOption is like Lookup but gives None when key is missing.
+func LookupOption(a *Association, key interface{}) Option {
+	return OptionOf(a.Get(key))
+}
+
+func KeySort(a *Association, less ...interface{}) *Association {
+	var keys interface{}
+	switch len(less) {
+	case 0:
+		keys = Sort(a.keys)
+	case 1:
+		keys = Sort(a.keys, less[0])
+	default:
+		msg := fmt.Sprintf("KeySort: KeySort called with %v ordering functions; at most 1 is expected.", len(less))
+		panic(msg)
+	}
+
+	b := NewAssociation()
+	for _, key := range keys.([]interface{}) {
+		b.Set(key, a.values[key])
+	}
+	return b
+}
+
+func KeySelect(a *Association, f interface{}) *Association {
+	fv := reflect.ValueOf(f)
+	mustBe(fv, reflect.Func)
+
+	b := NewAssociation()
+	for _, key := range a.keys {
+		if callAssociationFunc("KeySelect", fv, key).Interface().(bool) {
+			b.Set(key, a.values[key])
+		}
+	}
+	return b
+}
+
+func KeyDrop(a *Association, keys ...interface{}) *Association {
+	b := NewAssociation(a.Rules()...)
+	for _, key := range keys {
+		b.Delete(key)
+	}
+	return b
+}
+
+func KeyTake(a *Association, keys ...interface{}) *Association {
+	b := NewAssociation()
+	for _, key := range keys {
+		if value, ok := a.Get(key); ok {
+			b.Set(key, value)
+		}
+	}
+	return b
+}
+
+func KeyMap(f interface{}, a *Association) *Association {
+	fv := reflect.ValueOf(f)
+	mustBe(fv, reflect.Func)
+
+	b := NewAssociation()
+	for _, key := range a.keys {
+		b.Set(callAssociationFunc("KeyMap", fv, key).Interface(), a.values[key])
+	}
+	return b
+}
+
+func Merge(assocs []*Association, combiner interface{}) *Association {
+	fv := reflect.ValueOf(combiner)
+	mustBe(fv, reflect.Func)
+
+	groups := NewAssociation()
+	for _, a := range assocs {
+		for _, key := range a.keys {
+			values, _ := groups.Get(key)
+			if values == nil {
+				values = []interface{}{}
+			}
+			groups.Set(key, append(values.([]interface{}), a.values[key]))
+		}
+	}
+
+	b := NewAssociation()
+	for _, key := range groups.keys {
+		b.Set(key, callAssociationFunc("Merge", fv, groups.values[key]).Interface())
+	}
+	return b
+}
+
+func callAssociationFunc(name string, fv reflect.Value, args ...interface{}) reflect.Value {
+	if fv.Type().NumIn() != len(args) || fv.Type().NumOut() != 1 {
+		msg := fmt.Sprintf("%v: function signature %v should have %v parameters and 1 result.", name, fv.Type(), len(args))
+		panic(msg)
+	}
+
+	ins := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		ins[i] = argumentValue(name, fv.Type().In(i), arg)
+	}
+	return fv.Call(ins)[0]
+}
+
+func argumentValue(name string, t reflect.Type, x interface{}) reflect.Value {
+	if v, ok := assignedValue(t, x); ok {
+		return v
+	}
+	msg := fmt.Sprintf("%v: %v's type should be %v.", name, x, t)
+	panic(msg)
+}
+
+// assignedValue gives x as a value of type t when x is assignable to t, a nil
+// x is the zero value of the nilable kinds.
+func assignedValue(t reflect.Type, x interface{}) (reflect.Value, bool) {
+	if x == nil {
+		switch t.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Slice, reflect.Map, reflect.Func, reflect.Chan:
+			return reflect.Zero(t), true
+		}
+	} else if reflect.TypeOf(x).AssignableTo(t) {
+		return reflect.ValueOf(x), true
+	}
+	return reflect.Value{}, false
+}
+
+func mapAssociation(fv reflect.Value, a *Association) *Association {
+	b := NewAssociation()
+	for _, key := range a.keys {
+		b.Set(key, callAssociationFunc("Map", fv, a.values[key]).Interface())
+	}
+	return b
+}
+
+func filterAssociation(fv reflect.Value, a *Association) *Association {
+	b := NewAssociation()
+	for _, key := range a.keys {
+		value := a.values[key]
+		if callAssociationFunc("Filter", fv, value).Interface().(bool) {
+			b.Set(key, value)
+		}
+	}
+	return b
+}
+
+func foldAssociation(fv reflect.Value, initial interface{}, a *Association) interface{} {
+	result := initial
+	for _, key := range a.keys {
+		result = callAssociationFunc("Fold", fv, result, a.values[key]).Interface()
+	}
+	return result
+}
+
+func positionInAssociation(a *Association, pattern interface{}) [][]interface{} {
+	results := [][]interface{}{}
+	for _, key := range a.keys {
+		if reflect.DeepEqual(a.values[key], pattern) {
+			results = append(results, []interface{}{key})
+		}
+	}
+	return results
+}
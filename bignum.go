@@ -0,0 +1,373 @@
+package fp
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+	"reflect"
+)
+
+// isBigNumber reports whether x is a *big.Int, *big.Rat or *big.Float.
+func isBigNumber(x interface{}) bool {
+	switch x.(type) {
+	case *big.Int, *big.Rat, *big.Float:
+		return true
+	default:
+		return false
+	}
+}
+
+// compareNumbers compares the real numbers a and b of any kinds exactly, it
+// gives -1, 0 or 1.
+func compareNumbers(name string, a interface{}, b interface{}) int {
+	ra, infA := exactRat(name, a)
+	rb, infB := exactRat(name, b)
+	if infA != 0 || infB != 0 {
+		switch {
+		case infA == infB:
+			return 0
+		case infA > infB:
+			return 1
+		default:
+			return -1
+		}
+	}
+	return ra.Cmp(rb)
+}
+
+// exactRat gives the real number x as a big.Rat without rounding. Infinities
+// give their sign as inf instead.
+func exactRat(name string, x interface{}) (r *big.Rat, inf int) {
+	switch x := x.(type) {
+	case *big.Int:
+		return new(big.Rat).SetInt(x), 0
+	case *big.Rat:
+		return x, 0
+	case *big.Float:
+		if x.IsInf() {
+			return nil, x.Sign()
+		}
+		r, _ := x.Rat(nil)
+		return r, 0
+	}
+	v := reflect.ValueOf(x)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return new(big.Rat).SetInt64(v.Int()), 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return new(big.Rat).SetUint64(v.Uint()), 0
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsInf(f, 0) {
+			return nil, int(math.Copysign(1, f))
+		}
+		if r := new(big.Rat).SetFloat64(f); r != nil {
+			return r, 0
+		}
+	}
+	msg := fmt.Sprintf("%v: %v should be a real number.", name, x)
+	panic(msg)
+}
+
+// bigFloat64 gives the big number x as the nearest float64.
+func bigFloat64(x interface{}) float64 {
+	switch x := x.(type) {
+	case *big.Int:
+		f, _ := new(big.Float).SetInt(x).Float64()
+		return f
+	case *big.Rat:
+		f, _ := x.Float64()
+		return f
+	case *big.Float:
+		f, _ := x.Float64()
+		return f
+	default:
+		panic("Should not happend")
+	}
+}
+
+func absBig(x interface{}) interface{} {
+	switch x := x.(type) {
+	case *big.Int:
+		return new(big.Int).Abs(x)
+	case *big.Rat:
+		return new(big.Rat).Abs(x)
+	default:
+		y := x.(*big.Float)
+		return new(big.Float).Abs(y)
+	}
+}
+
+// totalBig gives the exact sum of a list of big numbers, in the type of its
+// elements.
+func totalBig(sv reflect.Value) interface{} {
+	switch sv.Type().Elem() {
+	case reflect.TypeOf(&big.Int{}):
+		total := new(big.Int)
+		for i := 0; i < sv.Len(); i++ {
+			total.Add(total, sv.Index(i).Interface().(*big.Int))
+		}
+		return total
+	case reflect.TypeOf(&big.Rat{}):
+		total := new(big.Rat)
+		for i := 0; i < sv.Len(); i++ {
+			total.Add(total, sv.Index(i).Interface().(*big.Rat))
+		}
+		return total
+	default:
+		total := new(big.Float)
+		for i := 0; i < sv.Len(); i++ {
+			x := sv.Index(i).Interface().(*big.Float)
+			if x.Prec() > total.Prec() {
+				total.SetPrec(x.Prec())
+			}
+			total.Add(total, x)
+		}
+		return total
+	}
+}
+
+// isInteger reports whether x is of an integer kind.
+func isInteger(x interface{}) bool {
+	switch reflect.ValueOf(x).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	default:
+		return false
+	}
+}
+
+// powInteger gives x^y exactly for integers x and y >= 0 in the type of x,
+// or as a *big.Int when it doesn't fit.
+func powInteger(x interface{}, y interface{}) interface{} {
+	base, exponent := bigInteger("Pow", x), bigInteger("Pow", y)
+	if base.IsInt64() && exponent.IsUint64() {
+		b := base.Int64()
+		magnitude := uint64(b)
+		if b < 0 {
+			magnitude = -magnitude
+		}
+		e := exponent.Uint64()
+		if z, ok := powUint64(magnitude, e); ok {
+			v := reflect.New(reflect.TypeOf(x)).Elem()
+			negative := b < 0 && e%2 == 1
+			switch v.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				// Two's complement negation reaches down to -2^63.
+				n, fits := int64(z), z <= math.MaxInt64
+				if negative {
+					n, fits = int64(-z), z <= 1<<63
+				}
+				if fits && !v.OverflowInt(n) {
+					v.SetInt(n)
+					return v.Interface()
+				}
+			default:
+				if !v.OverflowUint(z) {
+					v.SetUint(z)
+					return v.Interface()
+				}
+			}
+		}
+	}
+	return new(big.Int).Exp(base, exponent, nil)
+}
+
+// powUint64 gives x^e by squaring, ok is false when it overflows.
+func powUint64(x uint64, e uint64) (z uint64, ok bool) {
+	z = 1
+	for ; e > 0; e >>= 1 {
+		if e&1 == 1 {
+			hi, lo := bits.Mul64(z, x)
+			if hi != 0 {
+				return 0, false
+			}
+			z = lo
+		}
+		if e > 1 {
+			hi, lo := bits.Mul64(x, x)
+			if hi != 0 {
+				return 0, false
+			}
+			x = lo
+		}
+	}
+	return z, true
+}
+
+// promoteBase gives the exact number x as a big number of the type of the big
+// exponent y when that is the more general, e.g. an int as a *big.Rat for a
+// *big.Rat y.
+func promoteBase(x interface{}, y interface{}) interface{} {
+	tier := tierOf("Pow", y)
+	if tierOf("Pow", x) >= tier {
+		return x
+	}
+	switch tier {
+	case bigIntTier:
+		return bigInteger("Pow", x)
+	case bigRatTier:
+		r, _ := exactRat("Pow", x)
+		return new(big.Rat).Set(r)
+	default:
+		return toBigFloat(x, y.(*big.Float).Prec())
+	}
+}
+
+// powBig gives x^y for a big number x and an integer y, which may be a big
+// number with an integer value, exactly for big.Int and big.Rat, and in the
+// precision of x for big.Float.
+func powBig(x interface{}, y interface{}) interface{} {
+	e, ok := integerExponent(y)
+	if !ok {
+		msg := fmt.Sprintf("Pow: %v should be an integer exponent of %v.", y, x)
+		panic(msg)
+	}
+	negative := e.Sign() < 0
+	e.Abs(e)
+
+	switch x := x.(type) {
+	case *big.Int:
+		z := new(big.Int).Exp(x, e, nil)
+		if negative {
+			if z.Sign() == 0 {
+				msg := fmt.Sprintf("Pow: %v^%v is infinite.", x, y)
+				panic(msg)
+			}
+			return new(big.Rat).SetFrac(big.NewInt(1), z)
+		}
+		return z
+	case *big.Rat:
+		num := new(big.Int).Exp(x.Num(), e, nil)
+		den := new(big.Int).Exp(x.Denom(), e, nil)
+		if negative {
+			if num.Sign() == 0 {
+				msg := fmt.Sprintf("Pow: %v^%v is infinite.", x, y)
+				panic(msg)
+			}
+			num, den = den, num
+		}
+		return new(big.Rat).SetFrac(num, den)
+	default:
+		base := new(big.Float).Copy(x.(*big.Float))
+		z := new(big.Float).SetPrec(base.Prec()).SetInt64(1)
+		for i := 0; i < e.BitLen(); i++ {
+			if e.Bit(i) == 1 {
+				z.Mul(z, base)
+			}
+			base.Mul(base, base)
+		}
+		if negative {
+			z.Quo(new(big.Float).SetPrec(z.Prec()).SetInt64(1), z)
+		}
+		return z
+	}
+}
+
+// integerExponent gives the integer y as a new *big.Int, ok is false unless y
+// is an integer or a big number with an integer value.
+func integerExponent(y interface{}) (e *big.Int, ok bool) {
+	switch y := y.(type) {
+	case *big.Int:
+		return new(big.Int).Set(y), true
+	case *big.Rat:
+		if y.IsInt() {
+			return new(big.Int).Set(y.Num()), true
+		}
+		return nil, false
+	case *big.Float:
+		if y.IsInt() {
+			z, _ := y.Int(nil)
+			return z, true
+		}
+		return nil, false
+	}
+	if isInteger(y) {
+		return bigInteger("Pow", y), true
+	}
+	return nil, false
+}
+
+// N gives the numerical value of x: a float64 or complex128 without digits,
+// and a *big.Float with a precision of digits decimal digits otherwise. It
+// maps over lists.
+func N(x interface{}, digits ...int) interface{} {
+	prec := uint(0)
+	switch len(digits) {
+	case 0:
+	case 1:
+		if digits[0] <= 0 {
+			msg := fmt.Sprintf("N: %v should be a positive number of digits.", digits[0])
+			panic(msg)
+		}
+		prec = uint(math.Ceil(float64(digits[0]) * math.Log2(10)))
+	default:
+		msg := fmt.Sprintf("N: N called with %v precisions; at most 1 is expected.", len(digits))
+		panic(msg)
+	}
+	return numericValue(x, prec).Interface()
+}
+
+func numericValue(x interface{}, prec uint) reflect.Value {
+	v := reflect.ValueOf(x)
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		ys := make([]reflect.Value, v.Len())
+		for i := range ys {
+			ys[i] = numericValue(v.Index(i).Interface(), prec)
+		}
+		elementType := reflect.TypeOf(0.0)
+		if prec > 0 {
+			elementType = reflect.TypeOf(&big.Float{})
+		}
+		if len(ys) > 0 {
+			elementType = ys[0].Type()
+		}
+		zs := reflect.MakeSlice(reflect.SliceOf(elementType), len(ys), len(ys))
+		for i, y := range ys {
+			if y.Type() != elementType {
+				// Mixed real and complex elements.
+				return numericInterfaces(ys)
+			}
+			zs.Index(i).Set(y)
+		}
+		return zs
+	}
+
+	if v.Kind() == reflect.Complex64 || v.Kind() == reflect.Complex128 {
+		if prec > 0 {
+			msg := fmt.Sprintf("N: %v has no arbitrary-precision value.", x)
+			panic(msg)
+		}
+		return reflect.ValueOf(v.Complex())
+	}
+	if prec == 0 {
+		return reflect.ValueOf(realValue("N", x))
+	}
+
+	f := new(big.Float).SetPrec(prec)
+	switch x := x.(type) {
+	case *big.Int:
+		f.SetInt(x)
+	case *big.Rat:
+		f.SetRat(x)
+	case *big.Float:
+		f.Set(x)
+	default:
+		if isInteger(x) {
+			f.SetInt(bigInteger("N", x))
+		} else {
+			f.SetFloat64(realValue("N", x))
+		}
+	}
+	return reflect.ValueOf(f)
+}
+
+func numericInterfaces(ys []reflect.Value) reflect.Value {
+	zs := make([]interface{}, len(ys))
+	for i, y := range ys {
+		zs[i] = y.Interface()
+	}
+	return reflect.ValueOf(zs)
+}
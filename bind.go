@@ -0,0 +1,176 @@
+package fp
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+var (
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+)
+
+// BindError tells which step of a Bind chain failed.
+type BindError struct {
+	Index     int
+	Signature string
+	Err       error
+}
+
+func (e *BindError) Error() string {
+	return fmt.Sprintf("Bind: step %v %v failed: %v", e.Index, e.Signature, e.Err)
+}
+
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+// Binder chains functions whose last result is an error. The other results of
+// each step are the arguments of the next one, and a step whose first
+// parameter is a context.Context gets the context of Run.
+type Binder struct {
+	fs      []interface{}
+	onPanic func(step int, r interface{}) error
+}
+
+func NewBinder(fs ...interface{}) *Binder {
+	if len(fs) == 0 {
+		panic("Bind: Bind called with 0 functions; at least 1 is expected.")
+	}
+	for i, f := range fs {
+		fv := reflect.ValueOf(f)
+		mustBe(fv, reflect.Func)
+		t := fv.Type()
+		if t.NumOut() == 0 || !isErrorInterface(t.Out(t.NumOut()-1)) {
+			msg := fmt.Sprintf("Bind: the last result of step %v %v should be an error interface.", i, signature(f))
+			panic(msg)
+		}
+		if i > 0 && !bindable(reflect.TypeOf(fs[i-1]), t) {
+			msg := fmt.Sprintf("Bind: step %v %v can't take the results of %v.", i, signature(f), signature(fs[i-1]))
+			panic(msg)
+		}
+	}
+	return &Binder{fs: fs}
+}
+
+// OnPanic gives a Binder which turns a panic in a step into the error given by
+// hook. When hook gives nil the panic goes on.
+func (b *Binder) OnPanic(hook func(step int, r interface{}) error) *Binder {
+	return &Binder{fs: b.fs, onPanic: hook}
+}
+
+// Run calls the steps in order and gives the results of the last one without
+// its error. It stops at the first error, or when ctx is done.
+func (b *Binder) Run(ctx context.Context, args ...interface{}) ([]interface{}, error) {
+	first := reflect.TypeOf(b.fs[0])
+	params := parameters(first)
+	if len(args) != len(params) {
+		msg := fmt.Sprintf("Bind: %v called with %v arguments; %v arguments are expected.", signature(b.fs[0]), len(args), len(params))
+		panic(msg)
+	}
+	values := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		values[i] = argumentValue("Bind", params[i], arg)
+	}
+
+	for i, f := range b.fs {
+		if err := ctx.Err(); err != nil {
+			return nil, &BindError{Index: i, Signature: signature(f), Err: err}
+		}
+		outs, err := b.call(ctx, i, values)
+		if err != nil {
+			return nil, &BindError{Index: i, Signature: signature(f), Err: err}
+		}
+		values = outs
+	}
+
+	results := make([]interface{}, len(values))
+	for i, value := range values {
+		results[i] = value.Interface()
+	}
+	return results, nil
+}
+
+func (b *Binder) call(ctx context.Context, i int, values []reflect.Value) (outs []reflect.Value, err error) {
+	if b.onPanic != nil {
+		defer func() {
+			if r := recover(); r != nil {
+				if err = b.onPanic(i, r); err == nil {
+					panic(r)
+				}
+			}
+		}()
+	}
+
+	fv := reflect.ValueOf(b.fs[i])
+	if takesContext(fv.Type()) {
+		values = append([]reflect.Value{reflect.ValueOf(&ctx).Elem()}, values...)
+	}
+	outs = fv.Call(values)
+	last := outs[len(outs)-1]
+	if !last.IsNil() {
+		return nil, last.Interface().(error)
+	}
+	return outs[:len(outs)-1], nil
+}
+
+// BindAll is like Bind but gives all results of the last function.
+func BindAll(fs ...interface{}) func(...interface{}) ([]interface{}, error) {
+	b := NewBinder(fs...)
+	return func(args ...interface{}) ([]interface{}, error) {
+		return b.Run(context.Background(), args...)
+	}
+}
+
+// BindContext is like Bind but threads ctx through the steps.
+func BindContext(fs ...interface{}) func(context.Context, ...interface{}) (interface{}, error) {
+	b := NewBinder(fs...)
+	mustGiveOneResult(fs[len(fs)-1])
+	return func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		results, err := b.Run(ctx, args...)
+		if err != nil {
+			return nil, err
+		}
+		return results[0], nil
+	}
+}
+
+func mustGiveOneResult(f interface{}) {
+	if reflect.TypeOf(f).NumOut() != 2 {
+		msg := fmt.Sprintf("Bind: the results of the last function %v should be (interface{}, error); use BindAll for other results.", signature(f))
+		panic(msg)
+	}
+}
+
+// isErrorInterface reports whether t is error or another interface which
+// implements it.
+func isErrorInterface(t reflect.Type) bool {
+	return t.Kind() == reflect.Interface && t.Implements(errorType)
+}
+
+func takesContext(t reflect.Type) bool {
+	return t.NumIn() > 0 && t.In(0) == contextType
+}
+
+// parameters gives the parameter types of f without a leading context.Context.
+func parameters(f reflect.Type) []reflect.Type {
+	params := inTypes(f)
+	if takesContext(f) {
+		return params[1:]
+	}
+	return params
+}
+
+func bindable(prev reflect.Type, f reflect.Type) bool {
+	params := parameters(f)
+	if prev.NumOut()-1 != len(params) {
+		return false
+	}
+	for i, param := range params {
+		if !prev.Out(i).AssignableTo(param) {
+			return false
+		}
+	}
+	return true
+}
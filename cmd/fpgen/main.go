@@ -0,0 +1,50 @@
+// Command fpgen writes type-specialized versions of fp functions for the
+// //fp:generate directives of a package. Run it with go generate:
+//
+//	//go:generate go run fp/cmd/fpgen
+//	//fp:generate Map[Order,Invoice] Filter[Order] Fold[Order,float64]
+//	//fp:generate Sort[Order] GroupBy[Order,string] Union[int]
+//
+// Supported functions are Map[T,U], Filter[T], Fold[T,R], Sort[T],
+// GroupBy[T,K], Union[T], Intersection[T], Complement[T] and
+// DeleteDuplicates[T]. The generated tests compare each function with its
+// reflection counterpart on values made by testing/quick.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"fp/internal/fpgen"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "directory of the package")
+	output := flag.String("o", "fp_gen.go", "output file, the tests go to the matching _test.go file")
+	fp := flag.String("fp", "fp", "import path of the fp package")
+	flag.Parse()
+
+	if err := run(*dir, *output, *fp); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(dir, output, fp string) error {
+	pkg, specs, err := fpgen.ScanDir(dir)
+	if err != nil {
+		return err
+	}
+	code, test, err := fpgen.Generate(pkg, fp, specs)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, output)
+	if err := os.WriteFile(path, code, 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(strings.TrimSuffix(path, ".go")+"_test.go", test, 0644)
+}
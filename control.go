@@ -0,0 +1,270 @@
+package fp
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+type thrown struct {
+	value  interface{}
+	tag    interface{}
+	tagged bool
+}
+
+func (t thrown) Error() string {
+	if t.tagged {
+		return fmt.Sprintf("Throw: uncaught Throw[%v, %v].", t.value, t.tag)
+	}
+	return fmt.Sprintf("Throw: uncaught Throw[%v].", t.value)
+}
+
+// caughtBy reports whether a Catch for tags stops t. A Catch without tags
+// only stops a Throw without a tag.
+func (t thrown) caughtBy(tags []interface{}) bool {
+	if len(tags) == 0 {
+		return !t.tagged
+	}
+	if !t.tagged {
+		return false
+	}
+	for _, tag := range tags {
+		if tag == t.tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Throw stops the evaluation up to the enclosing Catch for tag, or the
+// enclosing Catch or Scan without a tag, which gives value.
+func Throw(value interface{}, tag ...interface{}) {
+	switch len(tag) {
+	case 0:
+		panic(thrown{value: value})
+	case 1:
+		mustBeHashable("Throw", tag[0])
+		panic(thrown{value: value, tag: tag[0], tagged: true})
+	default:
+		msg := fmt.Sprintf("Throw: Throw called with %v tags; at most 1 is expected.", len(tag))
+		panic(msg)
+	}
+}
+
+// Catch calls f, which takes no arguments, and gives its result or the value
+// of the first Throw in it for one of tags. Other panics go on.
+func Catch(f interface{}, tags ...interface{}) interface{} {
+	fv := reflect.ValueOf(f)
+	mustBeThunk("Catch", fv)
+	for _, tag := range tags {
+		mustBeHashable("Catch", tag)
+	}
+
+	var result interface{}
+	value, caught := catchThrow(func() {
+		result = returnResult(fv, nil, reflect.ValueOf([]interface{}{}))
+	}, tags...)
+	if caught {
+		return value
+	}
+	return result
+}
+
+// catchThrow calls f and gives the value of a Throw in it for one of tags.
+func catchThrow(f func(), tags ...interface{}) (value interface{}, caught bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			t, ok := r.(thrown)
+			if !ok || !t.caughtBy(tags) {
+				panic(r)
+			}
+			value, caught = t.value, true
+		}
+	}()
+	f()
+	return nil, false
+}
+
+type reaper struct {
+	mu   sync.Mutex
+	tags []interface{}
+	sown *Association
+}
+
+func (r *reaper) accepts(tag interface{}, tagged bool) bool {
+	if len(r.tags) == 0 {
+		return true
+	}
+	if !tagged {
+		return false
+	}
+	for _, t := range r.tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *reaper) sow(value interface{}, tag interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	values, _ := r.sown.Get(tag)
+	if values == nil {
+		values = []interface{}{}
+	}
+	r.sown.Set(tag, append(values.([]interface{}), value))
+}
+
+// reapers keeps the stack of enclosing Reaps of each goroutine.
+var reapers = struct {
+	sync.Mutex
+	stacks map[int64][]*reaper
+}{stacks: map[int64][]*reaper{}}
+
+// Sow gives value to the innermost enclosing Reap which collects tag, and
+// gives value back. Without an enclosing Reap it does nothing else.
+//
+// A Reap encloses the Sows on its own goroutine and in the workers of the
+// ParallelMap and ParallelDo calls in it, which are given its Reaps. A value
+// sown on another goroutine, such as one started with go inside f, isn't
+// collected.
+func Sow(value interface{}, tag ...interface{}) interface{} {
+	var t interface{}
+	switch len(tag) {
+	case 0:
+	case 1:
+		mustBeHashable("Sow", tag[0])
+		t = tag[0]
+	default:
+		msg := fmt.Sprintf("Sow: Sow called with %v tags; at most 1 is expected.", len(tag))
+		panic(msg)
+	}
+
+	stack := currentReapers()
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i].accepts(t, len(tag) > 0) {
+			stack[i].sow(value, t)
+			break
+		}
+	}
+	return value
+}
+
+// Reap calls f, which takes no arguments, and gives its result and the values
+// sown during the call, see Sow. Without tags the values are grouped by their
+// tags in order of appearance, otherwise there is a list of values for each
+// tag.
+func Reap(f interface{}, tags ...interface{}) (interface{}, [][]interface{}) {
+	fv := reflect.ValueOf(f)
+	mustBeThunk("Reap", fv)
+	for _, tag := range tags {
+		mustBeHashable("Reap", tag)
+	}
+
+	r := &reaper{tags: tags, sown: NewAssociation()}
+	gid := goroutineID()
+	reapers.Lock()
+	reapers.stacks[gid] = append(reapers.stacks[gid], r)
+	reapers.Unlock()
+	defer func() {
+		reapers.Lock()
+		defer reapers.Unlock()
+		if stack := reapers.stacks[gid]; len(stack) > 1 {
+			reapers.stacks[gid] = stack[:len(stack)-1]
+		} else {
+			delete(reapers.stacks, gid)
+		}
+	}()
+
+	result := returnResult(fv, nil, reflect.ValueOf([]interface{}{}))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(tags) == 0 {
+		sown := make([][]interface{}, r.sown.Len())
+		for i, values := range r.sown.Values() {
+			sown[i] = values.([]interface{})
+		}
+		return result, sown
+	}
+	sown := make([][]interface{}, len(tags))
+	for i, tag := range tags {
+		values, _ := r.sown.Get(tag)
+		if values == nil {
+			values = []interface{}{}
+		}
+		sown[i] = values.([]interface{})
+	}
+	return result, sown
+}
+
+// currentReapers gives the stack of enclosing Reaps of the calling goroutine.
+// Without any Reap it doesn't look up the goroutine.
+func currentReapers() []*reaper {
+	reapers.Lock()
+	defer reapers.Unlock()
+	if len(reapers.stacks) == 0 {
+		return nil
+	}
+	return reapers.stacks[goroutineID()]
+}
+
+// goParallel calls worker(i) for i in [0, n) on goroutines of their own. The
+// workers sow into the Reaps of the caller, and the first panic in a worker,
+// such as a Throw, is raised again in the caller.
+func goParallel(n int, worker func(i int)) {
+	stack := currentReapers()
+	var wg sync.WaitGroup
+	var once sync.Once
+	var panicked interface{}
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					once.Do(func() { panicked = r })
+				}
+			}()
+			if len(stack) > 0 {
+				gid := goroutineID()
+				reapers.Lock()
+				reapers.stacks[gid] = append([]*reaper{}, stack...)
+				reapers.Unlock()
+				defer func() {
+					reapers.Lock()
+					delete(reapers.stacks, gid)
+					reapers.Unlock()
+				}()
+			}
+			worker(i)
+		}(i)
+	}
+	wg.Wait()
+	if panicked != nil {
+		panic(panicked)
+	}
+}
+
+func goroutineID() int64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	id, err := strconv.ParseInt(string(buf[:bytes.IndexByte(buf, ' ')]), 10, 64)
+	if err != nil {
+		panic(fmt.Sprintf("goroutineID: can't parse %q.", buf))
+	}
+	return id
+}
+
+func mustBeThunk(name string, fv reflect.Value) {
+	mustBe(fv, reflect.Func)
+	if fv.Type().NumIn() != 0 {
+		msg := fmt.Sprintf("%v: function signature %v should have no parameters.", name, fv.Type())
+		panic(msg)
+	}
+}
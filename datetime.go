@@ -24,3 +24,27 @@ func Timing(f interface{}) (float64, interface{}) {
 	}
 	return float64(elapsed) / float64(1000000000), r
 }
+
+// Clock is the source of time for Memoize, Retry, TimeConstrained and
+// CircuitBreaker, so tests can replace it.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+var SystemClock Clock = systemClock{}
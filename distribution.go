@@ -0,0 +1,666 @@
+package fp
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+)
+
+// Distribution is a probability distribution over the reals. PDF gives the
+// probability mass of discrete distributions.
+type Distribution interface {
+	PDF(x float64) float64
+	CDF(x float64) float64
+	// InverseCDF gives the smallest x with CDF(x) >= p.
+	InverseCDF(p float64) float64
+	Mean() float64
+	Variance() float64
+	// Random draws a value from rng.
+	Random(rng *rand.Rand) float64
+}
+
+// PDF gives the probability density, or mass, of dist at x, which may be a list.
+func PDF(dist Distribution, x interface{}) interface{} {
+	return mapReal("PDF", dist.PDF, x)
+}
+
+// CDF gives the probability that a value of dist is at most x, which may be a list.
+func CDF(dist Distribution, x interface{}) interface{} {
+	return mapReal("CDF", dist.CDF, x)
+}
+
+// InverseCDF gives the quantile of dist for the probability p, which may be a list.
+func InverseCDF(dist Distribution, p interface{}) interface{} {
+	return mapReal("InverseCDF", func(p float64) float64 {
+		mustBeProbability("InverseCDF", p)
+		return dist.InverseCDF(p)
+	}, p)
+}
+
+// RandomVariate draws n values of dist. Without rng it uses the global state
+// of SeedRandom, pass a seeded rng for reproducible values.
+func RandomVariate(dist Distribution, n int, rng ...*rand.Rand) []float64 {
+	if n < 0 {
+		msg := fmt.Sprintf("RandomVariate: %v should be a non-negative count.", n)
+		panic(msg)
+	}
+	xs := make([]float64, n)
+	withRand("RandomVariate", rng, func(r *rand.Rand) {
+		for i := range xs {
+			xs[i] = dist.Random(r)
+		}
+	})
+	return xs
+}
+
+func withRand(name string, rng []*rand.Rand, f func(r *rand.Rand)) {
+	switch len(rng) {
+	case 0:
+		currentRandomState().do(f)
+	case 1:
+		f(rng[0])
+	default:
+		msg := fmt.Sprintf("%v: %v called with %v sources; at most 1 is expected.", name, name, len(rng))
+		panic(msg)
+	}
+}
+
+func mapReal(name string, f func(float64) float64, x interface{}) interface{} {
+	v := reflect.ValueOf(x)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return f(realValue(name, x))
+	}
+	xs := realValues(name, x)
+	ys := make([]float64, len(xs))
+	for i, x := range xs {
+		ys[i] = f(x)
+	}
+	return ys
+}
+
+func mustBeProbability(name string, p float64) {
+	if !(p >= 0 && p <= 1) {
+		msg := fmt.Sprintf("%v: %v should be a probability between 0 and 1.", name, p)
+		panic(msg)
+	}
+}
+
+func mustBePositive(name string, parameter string, x float64) {
+	if !(x > 0) || math.IsInf(x, 1) {
+		msg := fmt.Sprintf("%v: %v should be positive but not %v.", name, parameter, x)
+		panic(msg)
+	}
+}
+
+type normal struct{ mu, sigma float64 }
+
+// NormalDistribution has mean mu and standard deviation sigma.
+func NormalDistribution(mu, sigma float64) Distribution {
+	mustBePositive("NormalDistribution", "sigma", sigma)
+	return normal{mu, sigma}
+}
+
+func (d normal) PDF(x float64) float64 {
+	z := (x - d.mu) / d.sigma
+	return math.Exp(-z*z/2) / (d.sigma * math.Sqrt(2*math.Pi))
+}
+
+func (d normal) CDF(x float64) float64 {
+	return math.Erfc(-(x-d.mu)/(d.sigma*math.Sqrt2)) / 2
+}
+
+func (d normal) InverseCDF(p float64) float64 {
+	return d.mu - d.sigma*math.Sqrt2*math.Erfcinv(2*p)
+}
+
+func (d normal) Mean() float64     { return d.mu }
+func (d normal) Variance() float64 { return d.sigma * d.sigma }
+
+func (d normal) Random(rng *rand.Rand) float64 {
+	return d.mu + d.sigma*rng.NormFloat64()
+}
+
+func (d normal) String() string {
+	return fmt.Sprintf("NormalDistribution(%v, %v)", d.mu, d.sigma)
+}
+
+type uniform struct{ min, max float64 }
+
+// UniformDistribution is uniform over [min, max].
+func UniformDistribution(min, max float64) Distribution {
+	if !(min < max) {
+		msg := fmt.Sprintf("UniformDistribution: %v should be less than %v.", min, max)
+		panic(msg)
+	}
+	return uniform{min, max}
+}
+
+func (d uniform) PDF(x float64) float64 {
+	if x < d.min || x > d.max {
+		return 0
+	}
+	return 1 / (d.max - d.min)
+}
+
+func (d uniform) CDF(x float64) float64 {
+	return math.Max(0, math.Min(1, (x-d.min)/(d.max-d.min)))
+}
+
+func (d uniform) InverseCDF(p float64) float64 {
+	return d.min + p*(d.max-d.min)
+}
+
+func (d uniform) Mean() float64 { return (d.min + d.max) / 2 }
+
+func (d uniform) Variance() float64 {
+	return (d.max - d.min) * (d.max - d.min) / 12
+}
+
+func (d uniform) Random(rng *rand.Rand) float64 {
+	return d.min + rng.Float64()*(d.max-d.min)
+}
+
+func (d uniform) String() string {
+	return fmt.Sprintf("UniformDistribution(%v, %v)", d.min, d.max)
+}
+
+type exponential struct{ lambda float64 }
+
+// ExponentialDistribution has the rate lambda.
+func ExponentialDistribution(lambda float64) Distribution {
+	mustBePositive("ExponentialDistribution", "lambda", lambda)
+	return exponential{lambda}
+}
+
+func (d exponential) PDF(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	return d.lambda * math.Exp(-d.lambda*x)
+}
+
+func (d exponential) CDF(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	return -math.Expm1(-d.lambda * x)
+}
+
+func (d exponential) InverseCDF(p float64) float64 {
+	return -math.Log1p(-p) / d.lambda
+}
+
+func (d exponential) Mean() float64     { return 1 / d.lambda }
+func (d exponential) Variance() float64 { return 1 / (d.lambda * d.lambda) }
+
+func (d exponential) Random(rng *rand.Rand) float64 {
+	return rng.ExpFloat64() / d.lambda
+}
+
+func (d exponential) String() string {
+	return fmt.Sprintf("ExponentialDistribution(%v)", d.lambda)
+}
+
+type poisson struct{ mu float64 }
+
+// PoissonDistribution has the mean mu, its values are integers.
+func PoissonDistribution(mu float64) Distribution {
+	mustBePositive("PoissonDistribution", "mu", mu)
+	return poisson{mu}
+}
+
+func (d poisson) PDF(x float64) float64 {
+	if x < 0 || x != math.Floor(x) {
+		return 0
+	}
+	lg, _ := math.Lgamma(x + 1)
+	return math.Exp(x*math.Log(d.mu) - d.mu - lg)
+}
+
+func (d poisson) CDF(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	return gammaQ(math.Floor(x)+1, d.mu)
+}
+
+func (d poisson) InverseCDF(p float64) float64 {
+	if p == 1 {
+		return math.Inf(1)
+	}
+	return discreteInverseCDF(d.CDF, p, math.Floor(d.mu), 0, math.Inf(1))
+}
+
+func (d poisson) Mean() float64     { return d.mu }
+func (d poisson) Variance() float64 { return d.mu }
+
+func (d poisson) Random(rng *rand.Rand) float64 {
+	return d.InverseCDF(rng.Float64())
+}
+
+func (d poisson) String() string {
+	return fmt.Sprintf("PoissonDistribution(%v)", d.mu)
+}
+
+type binomial struct {
+	n int
+	p float64
+}
+
+// BinomialDistribution counts the successes of n trials with probability p.
+func BinomialDistribution(n int, p float64) Distribution {
+	if n < 0 {
+		msg := fmt.Sprintf("BinomialDistribution: %v should be a non-negative count.", n)
+		panic(msg)
+	}
+	mustBeProbability("BinomialDistribution", p)
+	return binomial{n, p}
+}
+
+func (d binomial) PDF(x float64) float64 {
+	n := float64(d.n)
+	if x < 0 || x > n || x != math.Floor(x) {
+		return 0
+	}
+	if d.p == 0 || d.p == 1 {
+		if x == n*d.p {
+			return 1
+		}
+		return 0
+	}
+	a, _ := math.Lgamma(n + 1)
+	b, _ := math.Lgamma(x + 1)
+	c, _ := math.Lgamma(n - x + 1)
+	return math.Exp(a - b - c + x*math.Log(d.p) + (n-x)*math.Log1p(-d.p))
+}
+
+func (d binomial) CDF(x float64) float64 {
+	n := float64(d.n)
+	switch {
+	case x < 0:
+		return 0
+	case x >= n:
+		return 1
+	case d.p == 0:
+		return 1
+	case d.p == 1:
+		return 0
+	}
+	k := math.Floor(x)
+	return betaI(n-k, k+1, 1-d.p)
+}
+
+func (d binomial) InverseCDF(p float64) float64 {
+	return discreteInverseCDF(d.CDF, p, math.Floor(float64(d.n)*d.p), 0, float64(d.n))
+}
+
+func (d binomial) Mean() float64     { return float64(d.n) * d.p }
+func (d binomial) Variance() float64 { return float64(d.n) * d.p * (1 - d.p) }
+
+func (d binomial) Random(rng *rand.Rand) float64 {
+	return d.InverseCDF(rng.Float64())
+}
+
+func (d binomial) String() string {
+	return fmt.Sprintf("BinomialDistribution(%v, %v)", d.n, d.p)
+}
+
+type gammaDist struct{ alpha, beta float64 }
+
+// GammaDistribution has the shape alpha and the scale beta.
+func GammaDistribution(alpha, beta float64) Distribution {
+	mustBePositive("GammaDistribution", "alpha", alpha)
+	mustBePositive("GammaDistribution", "beta", beta)
+	return gammaDist{alpha, beta}
+}
+
+func (d gammaDist) PDF(x float64) float64 {
+	if x < 0 || (x == 0 && d.alpha > 1) {
+		return 0
+	}
+	if x == 0 && d.alpha == 1 {
+		return 1 / d.beta
+	}
+	lg, _ := math.Lgamma(d.alpha)
+	return math.Exp((d.alpha-1)*math.Log(x) - x/d.beta - lg - d.alpha*math.Log(d.beta))
+}
+
+func (d gammaDist) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return gammaP(d.alpha, x/d.beta)
+}
+
+func (d gammaDist) InverseCDF(p float64) float64 {
+	return continuousInverseCDF(d.CDF, p, 0, math.Inf(1))
+}
+
+func (d gammaDist) Mean() float64     { return d.alpha * d.beta }
+func (d gammaDist) Variance() float64 { return d.alpha * d.beta * d.beta }
+
+func (d gammaDist) Random(rng *rand.Rand) float64 {
+	return d.beta * standardGamma(d.alpha, rng)
+}
+
+func (d gammaDist) String() string {
+	return fmt.Sprintf("GammaDistribution(%v, %v)", d.alpha, d.beta)
+}
+
+// standardGamma draws from GammaDistribution(alpha, 1) with Marsaglia and Tsang's method.
+func standardGamma(alpha float64, rng *rand.Rand) float64 {
+	if alpha < 1 {
+		return standardGamma(alpha+1, rng) * math.Pow(rng.Float64(), 1/alpha)
+	}
+	d := alpha - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := rng.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := rng.Float64()
+		if u < 1-0.0331*x*x*x*x || math.Log(u) < x*x/2+d*(1-v+math.Log(v)) {
+			return d * v
+		}
+	}
+}
+
+type betaDist struct{ alpha, beta float64 }
+
+// BetaDistribution is over [0, 1] with the shapes alpha and beta.
+func BetaDistribution(alpha, beta float64) Distribution {
+	mustBePositive("BetaDistribution", "alpha", alpha)
+	mustBePositive("BetaDistribution", "beta", beta)
+	return betaDist{alpha, beta}
+}
+
+func (d betaDist) PDF(x float64) float64 {
+	if x < 0 || x > 1 {
+		return 0
+	}
+	return math.Exp((d.alpha-1)*math.Log(x) + (d.beta-1)*math.Log1p(-x) - logBeta(d.alpha, d.beta))
+}
+
+func (d betaDist) CDF(x float64) float64 {
+	switch {
+	case x <= 0:
+		return 0
+	case x >= 1:
+		return 1
+	}
+	return betaI(d.alpha, d.beta, x)
+}
+
+func (d betaDist) InverseCDF(p float64) float64 {
+	return continuousInverseCDF(d.CDF, p, 0, 1)
+}
+
+func (d betaDist) Mean() float64 { return d.alpha / (d.alpha + d.beta) }
+
+func (d betaDist) Variance() float64 {
+	s := d.alpha + d.beta
+	return d.alpha * d.beta / (s * s * (s + 1))
+}
+
+func (d betaDist) Random(rng *rand.Rand) float64 {
+	x := standardGamma(d.alpha, rng)
+	y := standardGamma(d.beta, rng)
+	return x / (x + y)
+}
+
+func (d betaDist) String() string {
+	return fmt.Sprintf("BetaDistribution(%v, %v)", d.alpha, d.beta)
+}
+
+type logNormal struct{ mu, sigma float64 }
+
+// LogNormalDistribution is the distribution of e^x where x has
+// NormalDistribution(mu, sigma).
+func LogNormalDistribution(mu, sigma float64) Distribution {
+	mustBePositive("LogNormalDistribution", "sigma", sigma)
+	return logNormal{mu, sigma}
+}
+
+func (d logNormal) PDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return normal{d.mu, d.sigma}.PDF(math.Log(x)) / x
+}
+
+func (d logNormal) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return normal{d.mu, d.sigma}.CDF(math.Log(x))
+}
+
+func (d logNormal) InverseCDF(p float64) float64 {
+	return math.Exp(normal{d.mu, d.sigma}.InverseCDF(p))
+}
+
+func (d logNormal) Mean() float64 { return math.Exp(d.mu + d.sigma*d.sigma/2) }
+
+func (d logNormal) Variance() float64 {
+	s2 := d.sigma * d.sigma
+	return math.Expm1(s2) * math.Exp(2*d.mu+s2)
+}
+
+func (d logNormal) Random(rng *rand.Rand) float64 {
+	return math.Exp(d.mu + d.sigma*rng.NormFloat64())
+}
+
+func (d logNormal) String() string {
+	return fmt.Sprintf("LogNormalDistribution(%v, %v)", d.mu, d.sigma)
+}
+
+type empirical struct{ xs []float64 }
+
+// EmpiricalDistribution gives each element of list the same probability.
+func EmpiricalDistribution(list interface{}) Distribution {
+	sv := reflect.ValueOf(list)
+	xs := sortedValues("EmpiricalDistribution", list)
+	mustNotBeEmpty("EmpiricalDistribution", list, sv, 1)
+	return empirical{xs}
+}
+
+func (d empirical) PDF(x float64) float64 {
+	lo := sort.SearchFloat64s(d.xs, x)
+	hi := lo
+	for hi < len(d.xs) && d.xs[hi] == x {
+		hi++
+	}
+	return float64(hi-lo) / float64(len(d.xs))
+}
+
+func (d empirical) CDF(x float64) float64 {
+	n := sort.Search(len(d.xs), func(i int) bool { return d.xs[i] > x })
+	return float64(n) / float64(len(d.xs))
+}
+
+func (d empirical) InverseCDF(p float64) float64 {
+	return quantile(d.xs, p, QuantileInverseCDF)
+}
+
+func (d empirical) Mean() float64 { return kahanSum(d.xs) / float64(len(d.xs)) }
+
+// Variance is the population variance of the elements.
+func (d empirical) Variance() float64 {
+	m := newMoments(d.xs)
+	return m.m2 / m.n
+}
+
+func (d empirical) Random(rng *rand.Rand) float64 {
+	return d.xs[rng.Intn(len(d.xs))]
+}
+
+func (d empirical) String() string {
+	return fmt.Sprintf("EmpiricalDistribution(%v)", d.xs)
+}
+
+// discreteInverseCDF gives the smallest integer k in [lo, hi] with
+// cdf(k) >= p, walking from start.
+func discreteInverseCDF(cdf func(float64) float64, p float64, start float64, lo float64, hi float64) float64 {
+	k := math.Max(lo, math.Min(hi, start))
+	if cdf(k) >= p {
+		for k > lo && cdf(k-1) >= p {
+			k--
+		}
+		return k
+	}
+	for k < hi && cdf(k) < p {
+		k++
+	}
+	return k
+}
+
+// continuousInverseCDF solves cdf(x) = p in [lo, hi] by bisection.
+func continuousInverseCDF(cdf func(float64) float64, p float64, lo float64, hi float64) float64 {
+	if p <= 0 {
+		return lo
+	}
+	if p >= 1 {
+		return hi
+	}
+	if math.IsInf(hi, 1) {
+		hi = 1
+		for cdf(hi) < p {
+			lo, hi = hi, 2*hi
+		}
+	}
+	for i := 0; i < 200 && lo < hi; i++ {
+		mid := lo + (hi-lo)/2
+		if mid == lo || mid == hi {
+			break
+		}
+		if cdf(mid) < p {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return hi
+}
+
+func logBeta(a, b float64) float64 {
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	return la + lb - lab
+}
+
+// gammaP is the regularized lower incomplete gamma function P(a, x).
+func gammaP(a, x float64) float64 {
+	if x < a+1 {
+		return gammaSeries(a, x)
+	}
+	return 1 - gammaFraction(a, x)
+}
+
+// gammaQ is the regularized upper incomplete gamma function 1 - P(a, x).
+func gammaQ(a, x float64) float64 {
+	if x < a+1 {
+		return 1 - gammaSeries(a, x)
+	}
+	return gammaFraction(a, x)
+}
+
+func gammaSeries(a, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	lg, _ := math.Lgamma(a)
+	ap, sum := a, 1/a
+	term := sum
+	for i := 0; i < 1000; i++ {
+		ap++
+		term *= x / ap
+		sum += term
+		if math.Abs(term) < math.Abs(sum)*1e-16 {
+			break
+		}
+	}
+	return sum * math.Exp(-x+a*math.Log(x)-lg)
+}
+
+// gammaFraction evaluates Q(a, x) with Lentz's continued fraction.
+func gammaFraction(a, x float64) float64 {
+	const tiny = 1e-300
+	lg, _ := math.Lgamma(a)
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for i := 1; i < 1000; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < 1e-16 {
+			break
+		}
+	}
+	return math.Exp(-x+a*math.Log(x)-lg) * h
+}
+
+// betaI is the regularized incomplete beta function I_x(a, b).
+func betaI(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	front := math.Exp(a*math.Log(x) + b*math.Log1p(-x) - logBeta(a, b))
+	if x < (a+1)/(a+b+2) {
+		return front * betaFraction(a, b, x) / a
+	}
+	return 1 - front*betaFraction(b, a, 1-x)/b
+}
+
+func betaFraction(a, b, x float64) float64 {
+	const tiny = 1e-300
+	c := 1.0
+	d := 1 - (a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m < 1000; m++ {
+		fm := float64(m)
+		for _, an := range []float64{
+			fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm)),
+			-(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1)),
+		} {
+			d = 1 + an*d
+			if math.Abs(d) < tiny {
+				d = tiny
+			}
+			c = 1 + an/c
+			if math.Abs(c) < tiny {
+				c = tiny
+			}
+			d = 1 / d
+			h *= d * c
+		}
+		if math.Abs(d*c-1) < 1e-16 {
+			break
+		}
+	}
+	return h
+}
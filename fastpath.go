@@ -0,0 +1,175 @@
+package fp
+
+import (
+	"math"
+	"reflect"
+	"sort"
+)
+
+// The functions in this file handle common concrete types of Map, Filter,
+// Fold and Sort without reflection. They report false for any other types,
+// which then take the reflective path.
+
+func mapFast(f interface{}, slice interface{}) (interface{}, bool) {
+	switch f := f.(type) {
+	case func(int) int:
+		if xs, ok := slice.([]int); ok {
+			ys := make([]int, len(xs))
+			for i, x := range xs {
+				ys[i] = f(x)
+			}
+			return ys, true
+		}
+	case func(int) float64:
+		if xs, ok := slice.([]int); ok {
+			ys := make([]float64, len(xs))
+			for i, x := range xs {
+				ys[i] = f(x)
+			}
+			return ys, true
+		}
+	case func(int) string:
+		if xs, ok := slice.([]int); ok {
+			ys := make([]string, len(xs))
+			for i, x := range xs {
+				ys[i] = f(x)
+			}
+			return ys, true
+		}
+	case func(float64) float64:
+		if xs, ok := slice.([]float64); ok {
+			ys := make([]float64, len(xs))
+			for i, x := range xs {
+				ys[i] = f(x)
+			}
+			return ys, true
+		}
+	case func(string) string:
+		if xs, ok := slice.([]string); ok {
+			ys := make([]string, len(xs))
+			for i, x := range xs {
+				ys[i] = f(x)
+			}
+			return ys, true
+		}
+	case func(string) int:
+		if xs, ok := slice.([]string); ok {
+			ys := make([]int, len(xs))
+			for i, x := range xs {
+				ys[i] = f(x)
+			}
+			return ys, true
+		}
+	}
+	return nil, false
+}
+
+func filterFast(f interface{}, slice interface{}) (interface{}, bool) {
+	switch f := f.(type) {
+	case func(int) bool:
+		if xs, ok := slice.([]int); ok {
+			ys := []int{}
+			for _, x := range xs {
+				if f(x) {
+					ys = append(ys, x)
+				}
+			}
+			return ys, true
+		}
+	case func(float64) bool:
+		if xs, ok := slice.([]float64); ok {
+			ys := []float64{}
+			for _, x := range xs {
+				if f(x) {
+					ys = append(ys, x)
+				}
+			}
+			return ys, true
+		}
+	case func(string) bool:
+		if xs, ok := slice.([]string); ok {
+			ys := []string{}
+			for _, x := range xs {
+				if f(x) {
+					ys = append(ys, x)
+				}
+			}
+			return ys, true
+		}
+	}
+	return nil, false
+}
+
+func foldFast(f interface{}, initial interface{}, slice interface{}) (interface{}, bool) {
+	switch f := f.(type) {
+	case func(int, int) int:
+		result, ok1 := initial.(int)
+		xs, ok2 := slice.([]int)
+		if ok1 && ok2 {
+			for _, x := range xs {
+				result = f(result, x)
+			}
+			return result, true
+		}
+	case func(float64, float64) float64:
+		result, ok1 := initial.(float64)
+		xs, ok2 := slice.([]float64)
+		if ok1 && ok2 {
+			for _, x := range xs {
+				result = f(result, x)
+			}
+			return result, true
+		}
+	case func(string, string) string:
+		result, ok1 := initial.(string)
+		xs, ok2 := slice.([]string)
+		if ok1 && ok2 {
+			for _, x := range xs {
+				result = f(result, x)
+			}
+			return result, true
+		}
+	}
+	return nil, false
+}
+
+// sortFast sorts []int, []float64 and []string in the order of Less or
+// Greater with the sort package. Lists with NaN take the reflective path,
+// since sort.Float64Slice puts NaN first while Less doesn't order it.
+func sortFast(slice interface{}, less interface{}) (interface{}, bool) {
+	lv := reflect.ValueOf(less)
+	if lv.Kind() != reflect.Func {
+		return nil, false
+	}
+
+	var data sort.Interface
+	var ys interface{}
+	switch xs := slice.(type) {
+	case []int:
+		zs := append([]int{}, xs...)
+		data, ys = sort.IntSlice(zs), zs
+	case []float64:
+		for _, x := range xs {
+			if math.IsNaN(x) {
+				return nil, false
+			}
+		}
+		zs := append([]float64{}, xs...)
+		data, ys = sort.Float64Slice(zs), zs
+	case []string:
+		zs := append([]string{}, xs...)
+		data, ys = sort.StringSlice(zs), zs
+	default:
+		return nil, false
+	}
+
+	switch lv.Pointer() {
+	case reflect.ValueOf(Less).Pointer():
+		sort.Sort(data)
+	case reflect.ValueOf(Greater).Pointer():
+		sort.Sort(sort.Reverse(data))
+	default:
+		return nil, false
+	}
+	return ys, true
+}
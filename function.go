@@ -1,62 +1,484 @@
 package fp
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"reflect"
 	"runtime"
 	"strings"
 )
 
+// ArgumentError is the panic value of Apply and Construct, and the other
+// functions calling f with arguments, when f doesn't accept them.
+type ArgumentError struct {
+	Name string
+	Func reflect.Type
+	// Index is the position of the argument, or -1 when the number of
+	// arguments is wrong.
+	Index    int
+	Expected reflect.Type
+	// Actual is nil for a nil argument.
+	Actual  reflect.Type
+	NumArgs int
+}
+
+func (e *ArgumentError) Error() string {
+	if e.Index < 0 {
+		expected := fmt.Sprint(e.Func.NumIn())
+		if e.Func.IsVariadic() {
+			expected = fmt.Sprintf("at least %v", e.Func.NumIn()-1)
+		}
+		return fmt.Sprintf("%v: %v called with %v arguments; %v arguments are expected.", e.Name, typeSignature(e.Func), e.NumArgs, expected)
+	}
+	actual := "nil"
+	if e.Actual != nil {
+		actual = e.Actual.String()
+	}
+	return fmt.Sprintf("%v: arguments[%v]'s type should be %v but not %v.", e.Name, e.Index, e.Expected, actual)
+}
+
 func Apply(f interface{}, expr interface{}) interface{} {
+	return apply("Apply", f, expr, false)
+}
+
+// ApplyConvert is like Apply but converts numbers to the parameter types of f.
+func ApplyConvert(f interface{}, expr interface{}) interface{} {
+	return apply("ApplyConvert", f, expr, true)
+}
+
+func apply(name string, f interface{}, expr interface{}, convert bool) interface{} {
 	sv := reflect.ValueOf(expr)
 	fv := reflect.ValueOf(f)
 	mustBe(fv, reflect.Func)
-	//if !(sv.Kind() == reflect.Array || sv.Kind() == reflect.Slice) {
-	//	return expr
-	//}
 	mustBeArraySlice(sv)
 
-	if !fv.Type().IsVariadic() {
-		for i := 0; i < fv.Type().NumIn(); i++ {
-			left := fv.Type().In(i)
-			right := reflect.ValueOf(sv.Index(i).Interface()).Type()
-			if left.String() != "interface {}" && left != right {
-				msg := fmt.Sprintf("Apply: arguments[%v]'s type should be %v but not %v.", i, left, right)
+	args := make([]interface{}, sv.Len())
+	for i := range args {
+		args[i] = sv.Index(i).Interface()
+	}
+	return returnResult(fv, argumentValues(name, fv, args, convert), sv)
+}
+
+func Construct(f interface{}, args ...interface{}) interface{} {
+	return construct("Construct", f, args, false)
+}
+
+// ConstructConvert is like Construct but converts numbers to the parameter
+// types of f.
+func ConstructConvert(f interface{}, args ...interface{}) interface{} {
+	return construct("ConstructConvert", f, args, true)
+}
+
+func construct(name string, f interface{}, args []interface{}, convert bool) interface{} {
+	fv := reflect.ValueOf(f)
+	mustBe(fv, reflect.Func)
+	return returnResult(fv, argumentValues(name, fv, args, convert), reflect.ValueOf(args))
+}
+
+// Through applies each function in fs to args, and gives the results in a
+// []interface{}. Functions with several results give them as a slice.
+func Through(fs interface{}, args ...interface{}) []interface{} {
+	sv := reflect.ValueOf(fs)
+	mustBeArraySlice(sv)
+
+	results := make([]interface{}, sv.Len())
+	for i := 0; i < sv.Len(); i++ {
+		fv := reflect.ValueOf(sv.Index(i).Interface())
+		mustBe(fv, reflect.Func)
+		results[i] = returnResult(fv, argumentValues("Through", fv, args, false), reflect.ValueOf(args))
+	}
+	return results
+}
+
+// Comap applies each function in fs to x. The functions should have the same
+// result type, which is the element type of the slice Comap gives.
+func Comap(fs interface{}, x interface{}) interface{} {
+	sv := reflect.ValueOf(fs)
+	mustBeArraySlice(sv)
+
+	var outType reflect.Type
+	fvs := make([]reflect.Value, sv.Len())
+	for i := range fvs {
+		fvs[i] = reflect.ValueOf(sv.Index(i).Interface())
+		mustBe(fvs[i], reflect.Func)
+		mustBeFuncShape("Comap", fvs[i], 1, 1)
+		if i == 0 {
+			outType = fvs[i].Type().Out(0)
+		} else if fvs[i].Type().Out(0) != outType {
+			msg := fmt.Sprintf("Comap: %v and %v should have the same result type.", fvs[0].Type(), fvs[i].Type())
+			panic(msg)
+		}
+	}
+	if outType == nil {
+		outType = reflect.TypeOf((*interface{})(nil)).Elem()
+	}
+
+	ys := reflect.MakeSlice(reflect.SliceOf(outType), len(fvs), len(fvs))
+	args := []interface{}{x}
+	for i, fv := range fvs {
+		if y := returnResult(fv, argumentValues("Comap", fv, args, false), reflect.ValueOf(args)); y != nil {
+			ys.Index(i).Set(reflect.ValueOf(y))
+		}
+	}
+	return ys.Interface()
+}
+
+// Thread applies f to the corresponding elements of the slices in args, and
+// passes the other arguments unchanged to every call. The slices should have
+// the same length.
+func Thread(f interface{}, args ...interface{}) interface{} {
+	fv := reflect.ValueOf(f)
+	mustBe(fv, reflect.Func)
+	t := fv.Type()
+	if t.IsVariadic() || t.NumIn() != len(args) {
+		msg := fmt.Sprintf("Thread: %v called with %v arguments; %v arguments are expected.", signature(f), len(args), t.NumIn())
+		panic(msg)
+	}
+
+	n := -1
+	threaded := make([]bool, len(args))
+	for i, arg := range args {
+		av := reflect.ValueOf(arg)
+		if (av.Kind() == reflect.Slice || av.Kind() == reflect.Array) && !av.Type().AssignableTo(t.In(i)) {
+			if n >= 0 && av.Len() != n {
+				msg := fmt.Sprintf("Thread: %v can't be threaded with lists of length %v.", arg, n)
 				panic(msg)
 			}
+			n = av.Len()
+			threaded[i] = true
 		}
 	}
+	if n < 0 {
+		return returnResult(fv, argumentValues("Thread", fv, args, false), reflect.ValueOf(args))
+	}
 
-	values := make([]reflect.Value, sv.Len(), sv.Len())//reflect.MakeSlice(elementType, sv.Len(), sv.Len())
-	for i := 0; i < len(values); i++ {
-		values[i] = reflect.ValueOf(sv.Index(i).Interface())
+	outType := reflect.TypeOf((*interface{})(nil)).Elem()
+	if t.NumOut() == 1 {
+		outType = t.Out(0)
 	}
+	ys := reflect.MakeSlice(reflect.SliceOf(outType), n, n)
+	xs := make([]interface{}, len(args))
+	for j := 0; j < n; j++ {
+		for i, arg := range args {
+			xs[i] = arg
+			if threaded[i] {
+				xs[i] = reflect.ValueOf(arg).Index(j).Interface()
+			}
+		}
+		if y := returnResult(fv, argumentValues("Thread", fv, xs, false), reflect.ValueOf(xs)); y != nil {
+			ys.Index(j).Set(reflect.ValueOf(y))
+		}
+	}
+	return ys.Interface()
+}
 
-	return returnResult(fv, values, sv)
+// ApplyAt replaces the parts of expr at level, 1 by default, by f applied to
+// their elements, like Mathematica's f @@@ expr.
+func ApplyAt(f interface{}, expr interface{}, level ...int) interface{} {
+	fv := reflect.ValueOf(f)
+	mustBe(fv, reflect.Func)
+	n := 1
+	switch len(level) {
+	case 0:
+	case 1:
+		n = level[0]
+	default:
+		msg := fmt.Sprintf("ApplyAt: ApplyAt called with %v levels; at most 1 is expected.", len(level))
+		panic(msg)
+	}
+	if n < 0 {
+		msg := fmt.Sprintf("ApplyAt: level %v should not be negative.", n)
+		panic(msg)
+	}
+
+	outType := reflect.TypeOf((*interface{})(nil)).Elem()
+	if fv.Type().NumOut() == 1 {
+		outType = fv.Type().Out(0)
+	}
+	return applyAtLevel(fv, reflect.ValueOf(expr), n, outType).Interface()
 }
 
-func Construct(f interface{}, args... interface{}) interface{} {
-	sv := reflect.ValueOf(args)
+func applyAtLevel(fv reflect.Value, v reflect.Value, level int, outType reflect.Type) reflect.Value {
+	mustBeArraySlice(v)
+	if level == 0 {
+		args := make([]interface{}, v.Len())
+		for i := range args {
+			args[i] = v.Index(i).Interface()
+		}
+		return resultValue(fv.Call(argumentValues("ApplyAt", fv, args, false)), outType)
+	}
+
+	t := outType
+	for i := 1; i < level; i++ {
+		t = reflect.SliceOf(t)
+	}
+	ys := reflect.MakeSlice(reflect.SliceOf(t), v.Len(), v.Len())
+	for i := 0; i < v.Len(); i++ {
+		ys.Index(i).Set(applyAtLevel(fv, reflect.ValueOf(v.Index(i).Interface()), level-1, outType))
+	}
+	return ys
+}
+
+// resultValue gives the single result of a call as a value of outType, and
+// several results as a slice of their type, or of interface{} when their
+// types differ.
+func resultValue(results []reflect.Value, outType reflect.Type) reflect.Value {
+	switch len(results) {
+	case 0:
+		return reflect.Zero(outType)
+	case 1:
+		return results[0]
+	}
+	t := results[0].Type()
+	for _, result := range results[1:] {
+		if result.Type() != t {
+			t = outType
+		}
+	}
+	ys := reflect.MakeSlice(reflect.SliceOf(t), len(results), len(results))
+	for i, result := range results {
+		ys.Index(i).Set(result)
+	}
+	return ys
+}
+
+// argumentValues checks args against the parameters of fv, and panics with an
+// ArgumentError when fv doesn't accept them. With convert, numbers are
+// converted to the numeric parameter types.
+func argumentValues(name string, fv reflect.Value, args []interface{}, convert bool) []reflect.Value {
+	t := fv.Type()
+	numFixed := t.NumIn()
+	if t.IsVariadic() {
+		numFixed--
+	}
+	if len(args) < numFixed || (!t.IsVariadic() && len(args) > numFixed) {
+		panic(&ArgumentError{Name: name, Func: t, Index: -1, NumArgs: len(args)})
+	}
+
+	values := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		if i < numFixed {
+			values[i] = checkedArgument(name, fv, i, t.In(i), arg, convert)
+		} else {
+			values[i] = checkedArgument(name, fv, i, t.In(numFixed).Elem(), arg, convert)
+		}
+	}
+	return values
+}
+
+func checkedArgument(name string, fv reflect.Value, i int, t reflect.Type, x interface{}, convert bool) reflect.Value {
+	if v, ok := assignedValue(t, x); ok {
+		return v
+	}
+	if convert && x != nil {
+		if v, ok := convertedNumber(reflect.ValueOf(x), t); ok {
+			return v
+		}
+	}
+	panic(&ArgumentError{Name: name, Func: fv.Type(), Index: i, Expected: t, Actual: reflect.TypeOf(x)})
+}
+
+// convertedNumber converts the number v to the numeric type t, unless the
+// conversion loses its value, e.g. 1.5 to int, 300 to uint8 or -1 to uint.
+func convertedNumber(v reflect.Value, t reflect.Type) (reflect.Value, bool) {
+	if !isNumberKind(v.Kind()) || !isNumberKind(t.Kind()) {
+		return reflect.Value{}, false
+	}
+	y := v.Convert(t)
+	back := y.Convert(v.Type())
+	if (back.Interface() == v.Interface() && isNegative(v) == isNegative(y)) || (isNaN(v) && isNaN(y)) {
+		return y, true
+	}
+	return reflect.Value{}, false
+}
+
+func isNegative(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() < 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() < 0
+	}
+	return false
+}
+
+func isNaN(v reflect.Value) bool {
+	k := v.Kind()
+	return (k == reflect.Float32 || k == reflect.Float64) && math.IsNaN(v.Float())
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+type slot struct{}
+
+// Slot marks an argument of Partial which is supplied later by the caller.
+var Slot = slot{}
+
+func Curry(f interface{}) interface{} {
 	fv := reflect.ValueOf(f)
 	mustBe(fv, reflect.Func)
+	if fv.Type().IsVariadic() {
+		msg := fmt.Sprintf("Curry: %v is variadic and can't be curried.", signature(f))
+		panic(msg)
+	}
+	if fv.Type().NumIn() <= 1 {
+		return f
+	}
+	return curry(fv, []reflect.Value{}).Interface()
+}
 
-	if !fv.Type().IsVariadic() {
-		for i := 0; i < fv.Type().NumIn(); i++ {
-			left := fv.Type().In(i)
-			right := reflect.ValueOf(sv.Index(i).Interface()).Type()
-			if left.String() != "interface {}" && left != right {
-				msg := fmt.Sprintf("Construct: arguments[%v]'s type should be %v but not %v.", i, left, right)
-				panic(msg)
+func curry(fv reflect.Value, args []reflect.Value) reflect.Value {
+	t := fv.Type()
+	return reflect.MakeFunc(curriedType(t, len(args)), func(ins []reflect.Value) []reflect.Value {
+		values := append(append([]reflect.Value{}, args...), ins[0])
+		if len(values) == t.NumIn() {
+			return fv.Call(values)
+		}
+		return []reflect.Value{curry(fv, values)}
+	})
+}
+
+func curriedType(t reflect.Type, i int) reflect.Type {
+	outs := outTypes(t)
+	if i < t.NumIn()-1 {
+		outs = []reflect.Type{curriedType(t, i+1)}
+	}
+	return reflect.FuncOf([]reflect.Type{t.In(i)}, outs, false)
+}
+
+func inTypes(t reflect.Type) []reflect.Type {
+	ins := make([]reflect.Type, t.NumIn())
+	for i := 0; i < len(ins); i++ {
+		ins[i] = t.In(i)
+	}
+	return ins
+}
+
+func outTypes(t reflect.Type) []reflect.Type {
+	outs := make([]reflect.Type, t.NumOut())
+	for i := 0; i < len(outs); i++ {
+		outs[i] = t.Out(i)
+	}
+	return outs
+}
+
+// Partial fixes the leading arguments of f. Arguments given as Slot, and the
+// parameters after the given arguments, stay open and make up the signature
+// of the returned function.
+func Partial(f interface{}, args ...interface{}) interface{} {
+	fv := reflect.ValueOf(f)
+	mustBe(fv, reflect.Func)
+	t := fv.Type()
+
+	fixed := t.NumIn()
+	if t.IsVariadic() {
+		fixed--
+	}
+	if len(args) > fixed && !t.IsVariadic() {
+		msg := fmt.Sprintf("Partial: %v called with %v arguments; at most %v arguments are expected.", signature(f), len(args), fixed)
+		panic(msg)
+	}
+
+	paramType := func(i int) reflect.Type {
+		if i < fixed {
+			return t.In(i)
+		}
+		return t.In(t.NumIn() - 1).Elem()
+	}
+
+	values := make([]reflect.Value, len(args))
+	slots := []int{}
+	ins := []reflect.Type{}
+	for i, arg := range args {
+		if arg == Slot {
+			slots = append(slots, i)
+			ins = append(ins, paramType(i))
+			continue
+		}
+		values[i] = argumentValue("Partial", paramType(i), arg)
+	}
+	for i := len(args); i < fixed; i++ {
+		ins = append(ins, t.In(i))
+	}
+	if t.IsVariadic() {
+		ins = append(ins, t.In(t.NumIn()-1))
+	}
+
+	ft := reflect.FuncOf(ins, outTypes(t), t.IsVariadic())
+	return reflect.MakeFunc(ft, func(rest []reflect.Value) []reflect.Value {
+		all := append([]reflect.Value{}, values...)
+		for i, j := range slots {
+			all[j] = rest[i]
+		}
+		rest = rest[len(slots):]
+		if t.IsVariadic() {
+			tail := rest[len(rest)-1]
+			all = append(all, rest[:len(rest)-1]...)
+			for i := 0; i < tail.Len(); i++ {
+				all = append(all, tail.Index(i))
 			}
+		} else {
+			all = append(all, rest...)
 		}
+		return fv.Call(all)
+	}).Interface()
+}
+
+// OperatorApplied gives the operator form of f: OperatorApplied(f)(y...)(x)
+// is f(x, y...).
+func OperatorApplied(f interface{}) interface{} {
+	fv := reflect.ValueOf(f)
+	mustBe(fv, reflect.Func)
+	t := fv.Type()
+	if t.NumIn() == 0 || t.IsVariadic() && t.NumIn() == 1 {
+		msg := fmt.Sprintf("OperatorApplied: %v should have a first parameter.", signature(f))
+		panic(msg)
 	}
 
-	values := make([]reflect.Value, sv.Len(), sv.Len())//reflect.MakeSlice(elementType, sv.Len(), sv.Len())
-	for i := 0; i < len(values); i++ {
-		values[i] = reflect.ValueOf(sv.Index(i).Interface())
+	inner := reflect.FuncOf([]reflect.Type{t.In(0)}, outTypes(t), false)
+	outer := reflect.FuncOf(inTypes(t)[1:], []reflect.Type{inner}, t.IsVariadic())
+	return reflect.MakeFunc(outer, func(rest []reflect.Value) []reflect.Value {
+		op := reflect.MakeFunc(inner, func(first []reflect.Value) []reflect.Value {
+			all := append([]reflect.Value{first[0]}, rest...)
+			if t.IsVariadic() {
+				return fv.CallSlice(all)
+			}
+			return fv.Call(all)
+		})
+		return []reflect.Value{op}
+	}).Interface()
+}
+
+// ReverseApplied gives a function which takes the parameters of f in reverse order.
+func ReverseApplied(f interface{}) interface{} {
+	fv := reflect.ValueOf(f)
+	mustBe(fv, reflect.Func)
+	t := fv.Type()
+	if t.IsVariadic() {
+		msg := fmt.Sprintf("ReverseApplied: %v is variadic and can't be reversed.", signature(f))
+		panic(msg)
 	}
 
-	return returnResult(fv, values, sv)
+	ins := inTypes(t)
+	for i, j := 0, len(ins)-1; i < j; i, j = i+1, j-1 {
+		ins[i], ins[j] = ins[j], ins[i]
+	}
+	return reflect.MakeFunc(reflect.FuncOf(ins, outTypes(t), false), func(args []reflect.Value) []reflect.Value {
+		values := make([]reflect.Value, len(args))
+		for i := range args {
+			values[len(args)-1-i] = args[i]
+		}
+		return fv.Call(values)
+	}).Interface()
 }
 
 func returnResult(fv reflect.Value, values []reflect.Value, sv reflect.Value) interface{} {
@@ -75,110 +497,121 @@ func returnResult(fv reflect.Value, values []reflect.Value, sv reflect.Value) in
 	}
 }
 
-func Composition(fs... interface{}) func(...interface{}) interface{} {
+// Composition gives the function f(g(h(...))). Results of each function are
+// checked against the parameters of the one before it when the composition is
+// built, and the composition has the parameters of the last function and the
+// results of the first one.
+func Composition(fs ...interface{}) interface{} {
 	if len(fs) == 0 {
-		return func (args... interface{}) interface{} {
-			if len(args) == 1 {
-				return Identity(args[0])
-			} else {
-				msg := "Identity: Identity called with #{len(args)} arguments; 1 argument is expected."
+		return func(args ...interface{}) interface{} {
+			if len(args) != 1 {
+				msg := fmt.Sprintf("Identity: Identity called with %v arguments; 1 argument is expected.", len(args))
 				panic(msg)
 			}
+			return Identity(args[0])
 		}
 	}
 
-	for i := 0; i < len(fs); i++ {
-		f := fs[i]
-		fv := reflect.ValueOf(f)
-		if  fv.Kind() != reflect.Func {
-			msg := fmt.Sprintf("Composition: %v is not a function.", getFunctionName(f))
+	fvs := make([]reflect.Value, len(fs))
+	whole := make([]bool, len(fs))
+	for i, f := range fs {
+		fvs[i] = reflect.ValueOf(f)
+		if fvs[i].Kind() != reflect.Func {
+			msg := fmt.Sprintf("Composition: %v is not a function.", f)
+			panic(msg)
+		}
+		if i == 0 {
+			continue
+		}
+		if !composable(fvs[i-1].Type(), fvs[i].Type()) {
+			msg := fmt.Sprintf("Composition: %v can't take the results of %v.", signature(fs[i-1]), signature(fs[i]))
 			panic(msg)
 		}
+		whole[i-1] = fvs[i-1].Type().IsVariadic() && matchesAll(fvs[i-1].Type(), fvs[i].Type())
 	}
+	whole[len(fs)-1] = fvs[len(fs)-1].Type().IsVariadic()
 
-	fn := func(args... interface{}) interface{} {
-		sv := reflect.ValueOf(args)
-		var values []reflect.Value = make([]reflect.Value, len(args), len(args))
-		for i := 0; i < len(values); i++ {
-			values[i] = reflect.ValueOf(args[i])
-		}
-		for i := len(fs) - 1; i >= 0; i-- {
-			f := fs[i]
-			fv := reflect.ValueOf(f)
-			if i > 0 {
-				values = fv.Call(values)
+	first := fvs[0].Type()
+	last := fvs[len(fvs)-1].Type()
+	t := reflect.FuncOf(inTypes(last), outTypes(first), last.IsVariadic())
+	return reflect.MakeFunc(t, func(args []reflect.Value) []reflect.Value {
+		values := args
+		for i := len(fvs) - 1; i >= 0; i-- {
+			if whole[i] {
+				values = fvs[i].CallSlice(values)
+			} else {
+				values = fvs[i].Call(values)
 			}
 		}
+		return values
+	}).Interface()
+}
 
-		return returnResult(reflect.ValueOf(fs[0]), values, sv)
+// RightComposition gives the function h(g(f(...))), so the functions run in
+// the order they are given.
+func RightComposition(fs ...interface{}) interface{} {
+	reversed := make([]interface{}, len(fs))
+	for i, f := range fs {
+		reversed[len(fs)-1-i] = f
 	}
-	return fn
+	return Composition(reversed...)
 }
 
-func getFunctionName(i interface{}) string {
-	return runtime.FuncForPC(reflect.ValueOf(i).Pointer()).Name()
-}
+// composable reports whether f can take the results of g. A variadic f takes
+// them element by element, or as a whole when its last result is a slice.
+func composable(f reflect.Type, g reflect.Type) bool {
+	if matchesAll(f, g) {
+		return true
+	}
+	if !f.IsVariadic() || g.NumOut() < f.NumIn()-1 {
+		return false
+	}
 
-func Bind(fs ...interface{}) func(...interface{}) (interface{}, error) {
-	checkBindArguments(fs)
-	return func(args ...interface{}) (interface{}, error) {
-		var values []reflect.Value = make([]reflect.Value, len(args))
-		for i := 0; i < len(args); i++ {
-			values[i] = reflect.ValueOf(args[i])
-		}
-		for _, f := range fs {
-			fv := reflect.ValueOf(f)
-			outs := fv.Call(values)
-			values = outs[:(len(outs) - 1)]
-			err, ok := outs[len(outs)-1].Interface().(error)
-			if ok && err != nil {
-				return nil, err
-			}
+	for i := 0; i < g.NumOut(); i++ {
+		var in reflect.Type
+		if i < f.NumIn()-1 {
+			in = f.In(i)
+		} else {
+			in = f.In(f.NumIn() - 1).Elem()
 		}
-		outputs := make([]interface{}, len(values)-1)
-		for i := 0; i < len(outputs); i++ {
-			outputs[i] = values[i].Interface()
+		if !g.Out(i).AssignableTo(in) {
+			return false
 		}
-		return values[0].Interface(), nil
 	}
+	return true
 }
 
-func checkBindArguments(fs []interface{}) {
-	for i := 0; i < len(fs); i++ {
-		fv := reflect.ValueOf(fs[i])
-		mustBe(fv, reflect.Func)
-		if i > 0 {
-			checkFunctionArgumentNumber(fs, i, fv)
-		}
-
-		if i == len(fs)-1 {
-			checkFunctionOutputArguments(fv)
+func matchesAll(f reflect.Type, g reflect.Type) bool {
+	if g.NumOut() != f.NumIn() {
+		return false
+	}
+	for i := 0; i < g.NumOut(); i++ {
+		if !g.Out(i).AssignableTo(f.In(i)) {
+			return false
 		}
 	}
+	return true
 }
 
-func checkFunctionOutputArguments(fv reflect.Value) {
-	if fv.Type().NumOut() != 2 {
-		panic("the output arguments of the last function should be (interface{}, error)")
-	}
-	errorInterface := reflect.TypeOf((*error)(nil)).Elem()
-	t := fv.Type().Out(1)
-	if !(t.Kind() == reflect.Interface && t.Implements(errorInterface)) {
-		panic("the output arguments of the last function should be (interface{}, error)")
-	}
+func getFunctionName(i interface{}) string {
+	return runtime.FuncForPC(reflect.ValueOf(i).Pointer()).Name()
 }
 
-func checkFunctionArgumentNumber(fs []interface{}, i int, fv reflect.Value) {
-	prev := reflect.ValueOf(fs[i-1])
-	if prev.Type().NumOut()-1 != fv.Type().NumIn() {
-		msg := fmt.Sprintf("argument #%v and argument %v doesn't match.", signature(fs[i-1]), signature(fs[i]))
-		panic(msg)
+// Bind chains functions whose last result is an error and stops at the first
+// error. The last function should give (interface{}, error).
+func Bind(fs ...interface{}) func(...interface{}) (interface{}, error) {
+	f := BindContext(fs...)
+	return func(args ...interface{}) (interface{}, error) {
+		return f(context.Background(), args...)
 	}
 }
 
 func signature(f interface{}) string {
-	t := reflect.TypeOf(f)
-	if t.Kind() != reflect.Func {
+	return typeSignature(reflect.TypeOf(f))
+}
+
+func typeSignature(t reflect.Type) string {
+	if t == nil || t.Kind() != reflect.Func {
 		return "<not a function>"
 	}
 
@@ -0,0 +1,230 @@
+// Package fpgen generates type-specialized versions of fp functions from
+// //fp:generate directives, e.g.
+//
+//	//fp:generate Map[Order,Invoice] Filter[Order] Sort[Order]
+//
+// The generated functions give the same results as their reflection
+// counterparts, and the generated tests check exactly that.
+package fpgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+const Directive = "//fp:generate"
+
+// arities is the number of type parameters of each supported function.
+var arities = map[string]int{
+	"Map":              2,
+	"Filter":           1,
+	"Fold":             2,
+	"Sort":             1,
+	"GroupBy":          2,
+	"Union":            1,
+	"Intersection":     1,
+	"Complement":       1,
+	"DeleteDuplicates": 1,
+}
+
+// ordered are the types Sort can order without a less function.
+var ordered = map[string]bool{
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"byte": true, "rune": true, "float32": true, "float64": true, "string": true,
+}
+
+// Spec is one function to generate, e.g. Map[Order,Invoice].
+type Spec struct {
+	Func  string
+	Types []string
+}
+
+// Name is the name of the generated function, e.g. MapOrderInvoice.
+func (s Spec) Name() string {
+	name := s.Func
+	for _, t := range s.Types {
+		name += typeName(t)
+	}
+	return name
+}
+
+func (s Spec) String() string {
+	return fmt.Sprintf("%v[%v]", s.Func, strings.Join(s.Types, ","))
+}
+
+func (s Spec) T() string { return s.Types[0] }
+
+func (s Spec) U() string { return s.Types[len(s.Types)-1] }
+
+func (s Spec) Ordered() bool { return ordered[s.T()] }
+
+// typeName turns a type expression into a part of an identifier,
+// e.g. *pkg.Order becomes PtrPkgOrder and []int becomes SliceInt.
+func typeName(t string) string {
+	replacer := strings.NewReplacer("*", " Ptr ", "[]", " Slice ", "map[", " Map ", "chan ", " Chan ", ".", " ", "]", " ")
+	name := ""
+	for _, word := range strings.Fields(replacer.Replace(t)) {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		name += string(runes)
+	}
+	return name
+}
+
+// ParseDirective parses the specs after //fp:generate, they are separated by spaces.
+func ParseDirective(line string) ([]Spec, error) {
+	specs := []Spec{}
+	for _, field := range strings.Fields(line) {
+		spec, err := parseSpec(field)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+func parseSpec(s string) (Spec, error) {
+	open := strings.Index(s, "[")
+	if open <= 0 || !strings.HasSuffix(s, "]") {
+		return Spec{}, fmt.Errorf("fpgen: %v should look like Func[T1,T2].", s)
+	}
+	spec := Spec{Func: s[:open], Types: splitTypes(s[open+1 : len(s)-1])}
+	arity, ok := arities[spec.Func]
+	if !ok {
+		return Spec{}, fmt.Errorf("fpgen: %v can't be generated.", spec.Func)
+	}
+	if len(spec.Types) != arity {
+		return Spec{}, fmt.Errorf("fpgen: %v has %v type arguments; %v are expected.", s, len(spec.Types), arity)
+	}
+	for _, t := range spec.Types {
+		if t == "" {
+			return Spec{}, fmt.Errorf("fpgen: %v has an empty type argument.", s)
+		}
+	}
+	return spec, nil
+}
+
+// splitTypes splits on the commas which aren't nested in brackets.
+func splitTypes(s string) []string {
+	types := []string{}
+	depth, start := 0, 0
+	for i, c := range s {
+		switch c {
+		case '[', '(', '{':
+			depth++
+		case ']', ')', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				types = append(types, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(types, s[start:])
+}
+
+// ScanDir gives the package name and the specs of the directives in the Go
+// files of dir, without duplicates and in the order they appear.
+func ScanDir(dir string) (string, []Spec, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return "", nil, err
+	}
+	sort.Strings(paths)
+
+	pkg := ""
+	specs := []Spec{}
+	seen := map[string]bool{}
+	fset := token.NewFileSet()
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return "", nil, err
+		}
+		file, err := parser.ParseFile(fset, path, src, parser.ParseComments|parser.PackageClauseOnly)
+		if err != nil {
+			return "", nil, err
+		}
+		pkg = file.Name.Name
+		for _, line := range strings.Split(string(src), "\n") {
+			line = strings.TrimSpace(line)
+			if !strings.HasPrefix(line, Directive) {
+				continue
+			}
+			found, err := ParseDirective(strings.TrimPrefix(line, Directive))
+			if err != nil {
+				return "", nil, fmt.Errorf("%v: %v", filepath.Base(path), err)
+			}
+			for _, spec := range found {
+				if !seen[spec.Name()] {
+					seen[spec.Name()] = true
+					specs = append(specs, spec)
+				}
+			}
+		}
+	}
+	if pkg == "" {
+		return "", nil, fmt.Errorf("fpgen: no Go files in %v.", dir)
+	}
+	return pkg, specs, nil
+}
+
+type file struct {
+	Package string
+	FP      string
+	Imports []string
+	Specs   []Spec
+}
+
+// Generate gives the formatted source of the functions and of their tests.
+// fp is the import path of the fp package.
+func Generate(pkg string, fp string, specs []Spec) ([]byte, []byte, error) {
+	if len(specs) == 0 {
+		return nil, nil, fmt.Errorf("fpgen: there is no %v directive in package %v.", Directive, pkg)
+	}
+	f := file{Package: pkg, FP: fp, Specs: specs}
+	for _, spec := range specs {
+		if spec.Func == "Sort" || spec.Func == "GroupBy" {
+			f.Imports = []string{fp}
+			break
+		}
+	}
+	code, err := execute(codeTemplate, f)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f.Imports = []string{"fmt", fp, "hash/fnv", "math/rand", "reflect", "testing", "testing/quick"}
+	test, err := execute(testTemplate, f)
+	if err != nil {
+		return nil, nil, err
+	}
+	return code, test, nil
+}
+
+func execute(t *template.Template, f file) ([]byte, error) {
+	buf := bytes.Buffer{}
+	if err := t.Execute(&buf, f); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("fpgen: generated code isn't valid Go: %v", err)
+	}
+	return src, nil
+}
@@ -0,0 +1,262 @@
+package fpgen
+
+import "text/template"
+
+var funcs = template.FuncMap{"typeName": typeName}
+
+var codeTemplate = template.Must(template.New("code").Funcs(funcs).Parse(`// Code generated by fpgen. DO NOT EDIT.
+
+package {{.Package}}
+{{with .Imports}}
+import ({{range .}}
+	"{{.}}"{{end}}
+)
+{{end}}
+{{range .Specs}}{{template "spec" .}}{{end}}
+
+{{define "spec"}}{{if eq .Func "Map"}}
+// {{.Name}} is Map specialized for {{.T}} and {{.U}}.
+func {{.Name}}(f func({{.T}}) {{.U}}, xs []{{.T}}) []{{.U}} {
+	ys := make([]{{.U}}, len(xs))
+	for i, x := range xs {
+		ys[i] = f(x)
+	}
+	return ys
+}
+{{else if eq .Func "Filter"}}
+// {{.Name}} is Filter specialized for {{.T}}.
+func {{.Name}}(f func({{.T}}) bool, xs []{{.T}}) []{{.T}} {
+	ys := []{{.T}}{}
+	for _, x := range xs {
+		if f(x) {
+			ys = append(ys, x)
+		}
+	}
+	return ys
+}
+{{else if eq .Func "Fold"}}
+// {{.Name}} is Fold specialized for {{.T}} and {{.U}}.
+func {{.Name}}(f func({{.U}}, {{.T}}) {{.U}}, initial {{.U}}, xs []{{.T}}) {{.U}} {
+	result := initial
+	for _, x := range xs {
+		result = f(result, x)
+	}
+	return result
+}
+{{else if eq .Func "Sort"}}
+type sortable{{typeName .T}} []{{.T}}
+
+func (xs sortable{{typeName .T}}) Len() int      { return len(xs) }
+func (xs sortable{{typeName .T}}) Swap(i, j int) { xs[i], xs[j] = xs[j], xs[i] }
+
+// {{.Name}} is Sort specialized for {{.T}}, it gives a sorted copy of xs.{{if .Ordered}}
+// A nil less sorts in canonical order.{{end}}
+func {{.Name}}(xs []{{.T}}, less func(a, b {{.T}}) bool) []{{.T}} {
+	ys := make([]{{.T}}, len(xs))
+	copy(ys, xs)
+	if less == nil {
+{{- if .Ordered}}
+		less = func(a, b {{.T}}) bool { return !(a > b) }
+{{- else}}
+		panic("{{.Name}}: less is nil; {{.T}} has no canonical order.")
+{{- end}}
+	}
+	fp.SortWith(sortable{{typeName .T}}(ys), func(i, j int) bool { return less(ys[i], ys[j]) })
+	return ys
+}
+{{else if eq .Func "GroupBy"}}
+// {{.Name}} is GroupBy specialized for {{.T}} and {{.U}}, the values of the
+// association are []{{.T}}.
+func {{.Name}}(f func({{.T}}) {{.U}}, xs []{{.T}}) *fp.Association {
+	groups := fp.NewAssociation()
+	for _, x := range xs {
+		key := f(x)
+		group, ok := groups.Get(key)
+		if !ok {
+			group = make([]{{.T}}, 0, 1)
+		}
+		groups.Set(key, append(group.([]{{.T}}), x))
+	}
+	return groups
+}
+{{else if eq .Func "Union"}}
+// {{.Name}} is Union specialized for {{.T}}.
+func {{.Name}}(lists ...[]{{.T}}) []{{.T}} {
+	seen := map[{{.T}}]bool{}
+	ys := []{{.T}}{}
+	for _, xs := range lists {
+		for _, x := range xs {
+			if !seen[x] {
+				seen[x] = true
+				ys = append(ys, x)
+			}
+		}
+	}
+	return ys
+}
+{{else if eq .Func "DeleteDuplicates"}}
+// {{.Name}} is DeleteDuplicates specialized for {{.T}}.
+func {{.Name}}(xs []{{.T}}) []{{.T}} {
+	seen := map[{{.T}}]bool{}
+	ys := []{{.T}}{}
+	for _, x := range xs {
+		if !seen[x] {
+			seen[x] = true
+			ys = append(ys, x)
+		}
+	}
+	return ys
+}
+{{else if eq .Func "Intersection"}}
+// {{.Name}} is Intersection specialized for {{.T}}, the result follows the
+// order of the last list.
+func {{.Name}}(lists ...[]{{.T}}) []{{.T}} {
+	ys := []{{.T}}{}
+	if len(lists) == 0 {
+		return ys
+	}
+	common := map[{{.T}}]bool{}
+	for _, x := range lists[0] {
+		if !common[x] {
+			common[x] = true
+			ys = append(ys, x)
+		}
+	}
+	for _, xs := range lists[1:] {
+		next := map[{{.T}}]bool{}
+		ys = []{{.T}}{}
+		for _, x := range xs {
+			if common[x] && !next[x] {
+				next[x] = true
+				ys = append(ys, x)
+			}
+		}
+		common = next
+	}
+	return ys
+}
+{{else if eq .Func "Complement"}}
+// {{.Name}} is Complement specialized for {{.T}}.
+func {{.Name}}(xs []{{.T}}, ys []{{.T}}) []{{.T}} {
+	seen := map[{{.T}}]bool{}
+	for _, y := range ys {
+		seen[y] = true
+	}
+	zs := []{{.T}}{}
+	for _, x := range xs {
+		if !seen[x] {
+			seen[x] = true
+			zs = append(zs, x)
+		}
+	}
+	return zs
+}
+{{end}}{{end}}`))
+
+var testTemplate = template.Must(template.New("test").Funcs(funcs).Parse(`// Code generated by fpgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import ({{range .Imports}}
+	"{{.}}"{{end}}
+)
+
+// fpgenHash gives a hash of the values of args.
+func fpgenHash(args ...interface{}) uint64 {
+	h := fnv.New64a()
+	fmt.Fprint(h, args...)
+	return h.Sum64()
+}
+
+// fpgenValue gives a random value of type t which only depends on args,
+// so the functions under test are pure.
+func fpgenValue(t reflect.Type, args ...interface{}) reflect.Value {
+	v, ok := quick.Value(t, rand.New(rand.NewSource(int64(fpgenHash(args...)))))
+	if !ok {
+		panic(fmt.Sprintf("fpgen: testing/quick can't make a value of %v.", t))
+	}
+	return v
+}
+
+func fpgenCheck(t *testing.T, f interface{}) {
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+{{range .Specs}}{{template "spec" .}}{{end}}
+
+{{define "spec"}}{{if eq .Func "Map"}}
+func Test{{.Name}}(t *testing.T) {
+	f := func(x {{.T}}) {{.U}} {
+		return fpgenValue(reflect.TypeOf((*{{.U}})(nil)).Elem(), x).Interface().({{.U}})
+	}
+	fpgenCheck(t, func(xs []{{.T}}) bool {
+		return reflect.DeepEqual({{.Name}}(f, xs), fp.Map(f, xs))
+	})
+}
+{{else if eq .Func "Filter"}}
+func Test{{.Name}}(t *testing.T) {
+	f := func(x {{.T}}) bool { return fpgenHash(x)%2 == 0 }
+	fpgenCheck(t, func(xs []{{.T}}) bool {
+		return reflect.DeepEqual({{.Name}}(f, xs), fp.Filter(f, xs))
+	})
+}
+{{else if eq .Func "Fold"}}
+func Test{{.Name}}(t *testing.T) {
+	f := func(r {{.U}}, x {{.T}}) {{.U}} {
+		return fpgenValue(reflect.TypeOf((*{{.U}})(nil)).Elem(), r, x).Interface().({{.U}})
+	}
+	fpgenCheck(t, func(initial {{.U}}, xs []{{.T}}) bool {
+		return reflect.DeepEqual({{.Name}}(f, initial, xs), fp.Fold(f, initial, xs))
+	})
+}
+{{else if eq .Func "Sort"}}
+func Test{{.Name}}(t *testing.T) {
+	less := func(a, b {{.T}}) bool { return fpgenHash(a) < fpgenHash(b) }
+	fpgenCheck(t, func(xs []{{.T}}) bool {
+		expected := fp.Sort(xs, func(a, b interface{}) bool { return less(a.({{.T}}), b.({{.T}})) })
+		return reflect.DeepEqual({{.Name}}(xs, less), expected)
+	})
+{{- if .Ordered}}
+	fpgenCheck(t, func(xs []{{.T}}) bool {
+		return reflect.DeepEqual({{.Name}}(xs, nil), fp.Sort(xs))
+	})
+{{- end}}
+}
+{{else if eq .Func "GroupBy"}}
+func Test{{.Name}}(t *testing.T) {
+	f := func(x {{.T}}) {{.U}} {
+		return fpgenValue(reflect.TypeOf((*{{.U}})(nil)).Elem(), fpgenHash(x)%3).Interface().({{.U}})
+	}
+	fpgenCheck(t, func(xs []{{.T}}) bool {
+		return reflect.DeepEqual({{.Name}}(f, xs), fp.GroupBy(f, xs))
+	})
+}
+{{else if eq .Func "Union"}}
+func Test{{.Name}}(t *testing.T) {
+	fpgenCheck(t, func(xs, ys []{{.T}}) bool {
+		return reflect.DeepEqual({{.Name}}(xs, ys, xs), fp.Union(xs, ys, xs))
+	})
+}
+{{else if eq .Func "DeleteDuplicates"}}
+func Test{{.Name}}(t *testing.T) {
+	fpgenCheck(t, func(xs, ys []{{.T}}) bool {
+		zs := append(append(append([]{{.T}}{}, xs...), ys...), xs...)
+		return reflect.DeepEqual({{.Name}}(zs), fp.DeleteDuplicates(zs))
+	})
+}
+{{else if eq .Func "Intersection"}}
+func Test{{.Name}}(t *testing.T) {
+	fpgenCheck(t, func(xs, ys []{{.T}}) bool {
+		zs := append(append(append([]{{.T}}{}, ys...), xs...), xs...)
+		return reflect.DeepEqual({{.Name}}(zs, xs, zs), fp.Intersection(zs, xs, zs))
+	})
+}
+{{else if eq .Func "Complement"}}
+func Test{{.Name}}(t *testing.T) {
+	fpgenCheck(t, func(xs, ys []{{.T}}) bool {
+		zs := append(append(append([]{{.T}}{}, xs...), ys...), xs...)
+		return reflect.DeepEqual({{.Name}}(zs, ys), fp.Complement(zs, ys))
+	})
+}
+{{end}}{{end}}`))
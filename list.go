@@ -5,6 +5,7 @@ import (
 	"math"
 	"reflect"
 	"runtime"
+	"sort"
 	"sync"
 )
 
@@ -17,36 +18,36 @@ func isSlice(v reflect.Value) bool {
 }
 
 func typeQ(v reflect.Value, t reflect.Kind) bool {
-	pc, _, _, ok := runtime.Caller(1)
-	details := runtime.FuncForPC(pc)
-	if ok && details != nil {
-		return v.Kind() == t
-	}
-	return false
+	return v.Kind() == t
 }
 
 func mustBeMap(v reflect.Value) {
-	mustBe(v, reflect.Map)
+	if v.Kind() != reflect.Map {
+		panic(&reflect.ValueError{Method: callerName(2), Kind: v.Kind()})
+	}
 }
 
 func mustBeArraySlice(v reflect.Value) {
-	pc, _, _, ok := runtime.Caller(1)
-	details := runtime.FuncForPC(pc)
-	if ok && details != nil {
-		if !(v.Kind() == reflect.Array || v.Kind() == reflect.Slice) {
-			panic(&reflect.ValueError{details.Name(), v.Kind()})
-		}
+	if !(v.Kind() == reflect.Array || v.Kind() == reflect.Slice) {
+		panic(&reflect.ValueError{Method: callerName(2), Kind: v.Kind()})
 	}
 }
 
 func mustBe(v reflect.Value, kind reflect.Kind) {
-	pc, _, _, ok := runtime.Caller(1)
+	if v.Kind() != kind {
+		panic(&reflect.ValueError{Method: callerName(2), Kind: v.Kind()})
+	}
+}
+
+// callerName gives the name of the function skip frames above its caller. It
+// is slow, so it's only used to report errors.
+func callerName(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip)
 	details := runtime.FuncForPC(pc)
-	if ok && details != nil {
-		if v.Kind() != kind {
-			panic(&reflect.ValueError{details.Name(), v.Kind()})
-		}
+	if !ok || details == nil {
+		return "type error"
 	}
+	return details.Name()
 }
 
 func mustBeFuncSignature(sv reflect.Value, fv reflect.Value, numOut int, types ...reflect.Type) {
@@ -56,24 +57,61 @@ func mustBeFuncSignature(sv reflect.Value, fv reflect.Value, numOut int, types .
 	}
 }
 
-func panicTypeError(v reflect.Value) {
-	pc, _, _, ok := runtime.Caller(1)
-	details := runtime.FuncForPC(pc)
-	if ok && details != nil {
-		panic(&reflect.ValueError{details.Name(), v.Kind()})
-	} else {
-		panic(&reflect.ValueError{"type error", v.Kind()})
+// mustBeFoldSignature checks f of Fold, which may give any result it can take
+// back as its accumulator, e.g. Fold(Plus, 0, xs).
+func mustBeFoldSignature(name string, fv reflect.Value, resultType reflect.Type, elementType reflect.Type) {
+	if !verifyFuncSignature(fv, 1, resultType, elementType, nil) || !fv.Type().Out(0).AssignableTo(fv.Type().In(0)) {
+		msg := fmt.Sprintf("%v: function signature must be func(%v, %v) %v", name, resultType, elementType, resultType)
+		panic(msg)
 	}
 }
 
+func panicTypeError(v reflect.Value) {
+	panic(&reflect.ValueError{Method: callerName(2), Kind: v.Kind()})
+}
+
+// signatureKey identifies a call of verifyFuncSignature, types holds up to
+// maxCachedTypes parameter and result types.
+type signatureKey struct {
+	fn       reflect.Type
+	numOut   int
+	numTypes int
+	types    [maxCachedTypes]reflect.Type
+}
+
+const maxCachedTypes = 4
+
+// signatures caches the results of verifyFuncSignature by signatureKey.
+var signatures sync.Map
+
 func verifyFuncSignature(fv reflect.Value, numOut int, types ...reflect.Type) bool {
-	mustBe(fv, reflect.Func)
-	if (fv.Type().NumIn() != len(types)-numOut) || fv.Type().NumOut() != numOut {
+	if fv.Kind() != reflect.Func {
+		panic(&reflect.ValueError{Method: callerName(2), Kind: fv.Kind()})
+	}
+	if len(types) > maxCachedTypes {
+		return matchFuncSignature(fv.Type(), numOut, types)
+	}
+
+	key := signatureKey{fn: fv.Type(), numOut: numOut, numTypes: len(types)}
+	copy(key.types[:], types)
+	if ok, found := signatures.Load(key); found {
+		return ok.(bool)
+	}
+	ok := matchFuncSignature(fv.Type(), numOut, types)
+	signatures.Store(key, ok)
+	return ok
+}
+
+func matchFuncSignature(t reflect.Type, numOut int, types []reflect.Type) bool {
+	if (t.NumIn() != len(types)-numOut) || t.NumOut() != numOut {
 		return false
 	}
 
+	// An interface parameter accepts every type which implements it, e.g. the
+	// arithmetic functions such as Plus.
 	for i := 0; i < len(types)-numOut; i++ {
-		if fv.Type().In(i) != types[i] {
+		in := t.In(i)
+		if in != types[i] && !(in.Kind() == reflect.Interface && types[i].Implements(in)) {
 			return false
 		}
 	}
@@ -82,13 +120,38 @@ func verifyFuncSignature(fv reflect.Value, numOut int, types ...reflect.Type) bo
 	if numOut > 0 {
 		outType = types[len(types)-numOut]
 	}
-	return numOut == 0 || outType == nil || fv.Type().Out(0) == outType
+	return numOut == 0 || outType == nil || t.Out(0) == outType
 }
 
-func Map(f interface{}, slice interface{}) interface{} {
+// Map applies f to each element of slice. With a levelspec n, []int{n} or
+// []int{m, n}, f is applied to the parts of nested slices at levels 1 through
+// n, at level n only, or at levels m through n, and should accept all of them.
+func Map(f interface{}, slice interface{}, levelspec ...interface{}) interface{} {
 	sv := reflect.ValueOf(slice)
 	fv := reflect.ValueOf(f)
 	mustBe(fv, reflect.Func)
+	switch len(levelspec) {
+	case 0:
+	case 1:
+		mustBeArraySlice(sv)
+		mustBeFuncShape("Map", fv, 1, 1)
+		return parseLevelSpec("Map", levelspec[0]).apply(fv, sv)
+	default:
+		msg := fmt.Sprintf("Map: Map called with %v level specifications; at most 1 is expected.", len(levelspec))
+		panic(msg)
+	}
+	if a, ok := slice.(*Association); ok {
+		return mapAssociation(fv, a)
+	}
+	switch sv.Kind() {
+	case reflect.Map:
+		return mapMap(fv, sv)
+	case reflect.Struct:
+		return mapStruct(fv, sv)
+	}
+	if ys, ok := mapFast(f, slice); ok {
+		return ys
+	}
 	mustBeArraySlice(sv)
 
 	elementType := sv.Type().Elem()
@@ -102,6 +165,97 @@ func Map(f interface{}, slice interface{}) interface{} {
 	return ys.Interface()
 }
 
+// MapAll applies f to every part of expr, from the innermost elements of
+// nested slices to expr itself, wherever f accepts the part.
+func MapAll(f interface{}, expr interface{}) interface{} {
+	fv := reflect.ValueOf(f)
+	mustBe(fv, reflect.Func)
+	mustBeFuncShape("MapAll", fv, 1, 1)
+	spec := levelSpec{name: "MapAll", from: 0, to: math.MaxInt32}
+	return spec.apply(fv, reflect.ValueOf(expr))
+}
+
+type levelSpec struct {
+	name string
+	from int
+	to   int
+	// strict specs panic when f doesn't accept a part in the levels.
+	strict bool
+}
+
+func parseLevelSpec(name string, levelspec interface{}) levelSpec {
+	spec := levelSpec{name: name, strict: true}
+	switch x := levelspec.(type) {
+	case int:
+		spec.from, spec.to = 1, x
+	case []int:
+		switch len(x) {
+		case 1:
+			spec.from, spec.to = x[0], x[0]
+		case 2:
+			spec.from, spec.to = x[0], x[1]
+		default:
+			msg := fmt.Sprintf("%v: %v is not a valid level specification.", name, levelspec)
+			panic(msg)
+		}
+	default:
+		msg := fmt.Sprintf("%v: %v is not a valid level specification.", name, levelspec)
+		panic(msg)
+	}
+	if spec.from < 0 || spec.from > spec.to {
+		msg := fmt.Sprintf("%v: %v is not a valid level specification.", name, levelspec)
+		panic(msg)
+	}
+	return spec
+}
+
+func (spec levelSpec) apply(fv reflect.Value, sv reflect.Value) interface{} {
+	spec.resultType(fv, sv.Type(), 0)
+	return spec.mapLevel(fv, sv, 0).Interface()
+}
+
+// resultType gives the type which a part of type t at level depth becomes.
+func (spec levelSpec) resultType(fv reflect.Value, t reflect.Type, depth int) reflect.Type {
+	if depth < spec.to && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = reflect.SliceOf(spec.resultType(fv, t.Elem(), depth+1))
+	} else if depth < spec.from && spec.strict {
+		msg := fmt.Sprintf("%v: %v has no level %v.", spec.name, t, spec.from)
+		panic(msg)
+	}
+
+	switch {
+	case depth < spec.from:
+		return t
+	case t.AssignableTo(fv.Type().In(0)):
+		return fv.Type().Out(0)
+	case spec.strict:
+		msg := fmt.Sprintf("%v: function signature %v doesn't accept %v at level %v.", spec.name, fv.Type(), t, depth)
+		panic(msg)
+	default:
+		return t
+	}
+}
+
+func (spec levelSpec) mapLevel(fv reflect.Value, v reflect.Value, depth int) reflect.Value {
+	t := v.Type()
+	if depth < spec.to && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		ys := reflect.MakeSlice(reflect.SliceOf(spec.resultType(fv, t.Elem(), depth+1)), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			ys.Index(i).Set(spec.mapLevel(fv, v.Index(i), depth+1))
+		}
+		v = ys
+	}
+	if depth >= spec.from && v.Type().AssignableTo(fv.Type().In(0)) {
+		return fv.Call([]reflect.Value{v})[0]
+	}
+	return v
+}
+
+// ParallelMap applies f to each element of slice on goroutines of their own,
+// and gives the results in the order of slice. The workers sow into the Reaps
+// of the caller. When f panics, e.g. with a Throw, ParallelMap waits for the
+// other workers and panics with the first such value in the calling goroutine,
+// where Catch and recover see it.
 func ParallelMap(f interface{}, slice interface{}) interface{} {
 	sv := reflect.ValueOf(slice)
 	fv := reflect.ValueOf(f)
@@ -113,19 +267,12 @@ func ParallelMap(f interface{}, slice interface{}) interface{} {
 
 	ys := reflect.MakeSlice(reflect.SliceOf(fv.Type().Out(0)), sv.Len(), sv.Len())
 
-	var wg sync.WaitGroup
-	wg.Add(sv.Len())
-
 	worker := func(i int) {
-		defer wg.Done()
 		x := []reflect.Value{sv.Index(i)}
 		value := fv.Call(x)[0]
 		ys.Index(i).Set(value)
 	}
-	for i := 0; i < sv.Len(); i++ {
-		go worker(i)
-	}
-	wg.Wait()
+	goParallel(sv.Len(), worker)
 	return ys.Interface()
 }
 
@@ -188,10 +335,21 @@ func checkMapThreadArguments(f interface{}, slices []interface{}) {
 	}
 }
 
+// Do applies f to each element of slice. Over a map it visits the values in
+// key order when Greater compares the keys, and in Go's random map order
+// otherwise.
 func Do(f interface{}, slice interface{}) {
 	sv := reflect.ValueOf(slice)
 	fv := reflect.ValueOf(f)
 	mustBe(fv, reflect.Func)
+	switch sv.Kind() {
+	case reflect.Map:
+		doMap(fv, sv)
+		return
+	case reflect.Struct:
+		doStruct(fv, sv)
+		return
+	}
 	mustBeArraySlice(sv)
 
 	elementType := sv.Type().Elem()
@@ -206,6 +364,24 @@ func Do(f interface{}, slice interface{}) {
 	}
 }
 
+// Scan is like Do, but f may give results, which are discarded, and a Throw in
+// f stops the scan. Scan gives the thrown value, or nil.
+func Scan(f interface{}, expr interface{}) interface{} {
+	fv := reflect.ValueOf(f)
+	mustBe(fv, reflect.Func)
+	procedure := reflect.MakeFunc(reflect.FuncOf(inTypes(fv.Type()), nil, fv.Type().IsVariadic()), func(args []reflect.Value) []reflect.Value {
+		call(fv, args)
+		return nil
+	})
+
+	value, _ := catchThrow(func() {
+		Do(procedure.Interface(), expr)
+	})
+	return value
+}
+
+// ParallelDo calls f on each element of slice on goroutines of their own. The
+// workers sow and panic as in ParallelMap.
 func ParallelDo(f interface{}, slice interface{}) {
 	sv := reflect.ValueOf(slice)
 	fv := reflect.ValueOf(f)
@@ -215,56 +391,72 @@ func ParallelDo(f interface{}, slice interface{}) {
 	elementType := sv.Type().Elem()
 	mustBeFuncSignature(sv, fv, 0, elementType)
 
-	var wg sync.WaitGroup
-	wg.Add(sv.Len())
-
 	worker := func(i int) {
-		defer wg.Done()
 		x := []reflect.Value{sv.Index(i)}
 		fv.Call(x)
 	}
-	for i := 0; i < sv.Len(); i++ {
-		go worker(i)
-	}
-	wg.Wait()
+	goParallel(sv.Len(), worker)
 }
 
 func Filter(f interface{}, slice interface{}) interface{} {
 	sv := reflect.ValueOf(slice)
 	fv := reflect.ValueOf(f)
 	mustBe(fv, reflect.Func)
+	if a, ok := slice.(*Association); ok {
+		return filterAssociation(fv, a)
+	}
+	switch sv.Kind() {
+	case reflect.Map:
+		return filterMap(fv, sv)
+	case reflect.Struct:
+		return filterStruct(fv, sv)
+	}
+	if ys, ok := filterFast(f, slice); ok {
+		return ys
+	}
 	mustBeArraySlice(sv)
 
 	elementType := sv.Type().Elem()
 	mustBeFuncSignature(sv, fv, 1, elementType, reflect.ValueOf(true).Type())
 
-	ys := []interface{}{}
+	ys := reflect.MakeSlice(reflect.SliceOf(elementType), 0, 0)
 	for i := 0; i < sv.Len(); i++ {
 		x := sv.Index(i)
 		args := []reflect.Value{x}
-		if fv.Call(args)[0].Interface().(bool) {
-			ys = append(ys, x.Interface())
+		if fv.Call(args)[0].Bool() {
+			ys = reflect.Append(ys, x)
 		}
 	}
-
-	zs := reflect.MakeSlice(reflect.SliceOf(elementType), len(ys), len(ys))
-	for i := 0; i < zs.Len(); i++ {
-		zs.Index(i).Set(reflect.ValueOf(ys[i]))
-	}
-	return zs.Interface()
+	return ys.Interface()
 }
 
+var Select = Filter
+
 var Reduce = Fold
 
+// Fold gives f(...f(f(initial, x1), x2)..., xn) for the elements of slice. Over
+// a map it visits the values in the order of Do.
 func Fold(f interface{}, initial interface{}, slice interface{}) interface{} {
 	sv := reflect.ValueOf(slice)
 	fv := reflect.ValueOf(f)
 	mustBe(fv, reflect.Func)
+	if a, ok := slice.(*Association); ok {
+		return foldAssociation(fv, initial, a)
+	}
+	switch sv.Kind() {
+	case reflect.Map:
+		return foldMap(fv, initial, sv)
+	case reflect.Struct:
+		return foldStruct(fv, initial, sv)
+	}
+	if result, ok := foldFast(f, initial, slice); ok {
+		return result
+	}
 	mustBeArraySlice(sv)
 
 	elementType := sv.Type().Elem()
 	resultType := reflect.ValueOf(initial).Type()
-	mustBeFuncSignature(sv, fv, 1, resultType, elementType, resultType)
+	mustBeFoldSignature("Fold", fv, resultType, elementType)
 
 	var result = reflect.ValueOf(initial)
 	var ins [2]reflect.Value
@@ -281,6 +473,12 @@ func MapIndexed(f interface{}, slice interface{}) interface{} {
 	sv := reflect.ValueOf(slice)
 	fv := reflect.ValueOf(f)
 	mustBe(fv, reflect.Func)
+	switch sv.Kind() {
+	case reflect.Map:
+		return mapIndexedMap(fv, sv)
+	case reflect.Struct:
+		return mapIndexedStruct(fv, sv)
+	}
 	mustBeArraySlice(sv)
 
 	elementType := sv.Type().Elem()
@@ -296,6 +494,315 @@ func MapIndexed(f interface{}, slice interface{}) interface{} {
 	return ys.Interface()
 }
 
+func mapMap(fv reflect.Value, sv reflect.Value) interface{} {
+	mustBeFuncSignature(sv, fv, 1, sv.Type().Elem(), nil)
+
+	ys := reflect.MakeMapWithSize(reflect.MapOf(sv.Type().Key(), fv.Type().Out(0)), sv.Len())
+	for _, key := range sortedMapKeys(sv) {
+		x := []reflect.Value{sv.MapIndex(key)}
+		ys.SetMapIndex(key, fv.Call(x)[0])
+	}
+	return ys.Interface()
+}
+
+func filterMap(fv reflect.Value, sv reflect.Value) interface{} {
+	mustBeFuncSignature(sv, fv, 1, sv.Type().Elem(), reflect.TypeOf(true))
+
+	ys := reflect.MakeMap(sv.Type())
+	for _, key := range sortedMapKeys(sv) {
+		x := sv.MapIndex(key)
+		if fv.Call([]reflect.Value{x})[0].Interface().(bool) {
+			ys.SetMapIndex(key, x)
+		}
+	}
+	return ys.Interface()
+}
+
+func foldMap(fv reflect.Value, initial interface{}, sv reflect.Value) interface{} {
+	resultType := reflect.ValueOf(initial).Type()
+	mustBeFoldSignature("Fold", fv, resultType, sv.Type().Elem())
+
+	var result = reflect.ValueOf(initial)
+	for _, key := range sortedMapKeys(sv) {
+		result = fv.Call([]reflect.Value{result, sv.MapIndex(key)})[0]
+	}
+	return result.Interface()
+}
+
+func doMap(fv reflect.Value, sv reflect.Value) {
+	mustBeFuncSignature(sv, fv, 0, sv.Type().Elem())
+
+	for _, key := range sortedMapKeys(sv) {
+		fv.Call([]reflect.Value{sv.MapIndex(key)})
+	}
+}
+
+func mapIndexedMap(fv reflect.Value, sv reflect.Value) interface{} {
+	mustBeFuncSignature(sv, fv, 1, sv.Type().Elem(), sv.Type().Key(), nil)
+
+	ys := reflect.MakeMapWithSize(reflect.MapOf(sv.Type().Key(), fv.Type().Out(0)), sv.Len())
+	for _, key := range sortedMapKeys(sv) {
+		ys.SetMapIndex(key, fv.Call([]reflect.Value{sv.MapIndex(key), key})[0])
+	}
+	return ys.Interface()
+}
+
+// sortedMapKeys gives the keys of a map in canonical order when they are
+// comparable by Greater, so that iteration over such a map is reproducible.
+// Other keys, e.g. structs or pointers, are in Go's random map order.
+func sortedMapKeys(sv reflect.Value) []reflect.Value {
+	keys := sv.MapKeys()
+	for i := 0; i < len(keys); i++ {
+		x := keys[i].Interface()
+		if !isPrimitiveComparable(x) || reflect.ValueOf(x).Kind() != reflect.ValueOf(keys[0].Interface()).Kind() {
+			return keys
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return Greater(keys[j].Interface(), keys[i].Interface())
+	})
+	return keys
+}
+
+func mapStruct(fv reflect.Value, sv reflect.Value) interface{} {
+	mustBeFuncShape("Map", fv, 1, 1)
+	fields := exportedFields("Map", sv, fv.Type().In(0))
+
+	ys := reflect.MakeMapWithSize(reflect.MapOf(reflect.TypeOf(""), fv.Type().Out(0)), len(fields))
+	for _, field := range fields {
+		x := []reflect.Value{sv.FieldByIndex(field.Index)}
+		ys.SetMapIndex(reflect.ValueOf(field.Name), fv.Call(x)[0])
+	}
+	return ys.Interface()
+}
+
+func filterStruct(fv reflect.Value, sv reflect.Value) interface{} {
+	mustBeFuncShape("Filter", fv, 1, 1)
+	fields := exportedFields("Filter", sv, fv.Type().In(0))
+
+	ys := reflect.MakeMap(reflect.MapOf(reflect.TypeOf(""), fv.Type().In(0)))
+	for _, field := range fields {
+		x := sv.FieldByIndex(field.Index)
+		if fv.Call([]reflect.Value{x})[0].Interface().(bool) {
+			ys.SetMapIndex(reflect.ValueOf(field.Name), x)
+		}
+	}
+	return ys.Interface()
+}
+
+func foldStruct(fv reflect.Value, initial interface{}, sv reflect.Value) interface{} {
+	mustBeFuncShape("Fold", fv, 2, 1)
+	resultType := reflect.ValueOf(initial).Type()
+	if fv.Type().In(0) != resultType || fv.Type().Out(0) != resultType {
+		msg := fmt.Sprintf("Fold: function signature must be func(%v, FieldType) %v", resultType, resultType)
+		panic(msg)
+	}
+	fields := exportedFields("Fold", sv, fv.Type().In(1))
+
+	var result = reflect.ValueOf(initial)
+	for _, field := range fields {
+		result = fv.Call([]reflect.Value{result, sv.FieldByIndex(field.Index)})[0]
+	}
+	return result.Interface()
+}
+
+func doStruct(fv reflect.Value, sv reflect.Value) {
+	mustBeFuncShape("Do", fv, 1, 0)
+	fields := exportedFields("Do", sv, fv.Type().In(0))
+
+	for _, field := range fields {
+		fv.Call([]reflect.Value{sv.FieldByIndex(field.Index)})
+	}
+}
+
+func mapIndexedStruct(fv reflect.Value, sv reflect.Value) interface{} {
+	mustBeFuncShape("MapIndexed", fv, 2, 1)
+	if fv.Type().In(1) != reflect.TypeOf("") {
+		msg := fmt.Sprintf("MapIndexed: function signature must be func(FieldType, string) OutputElementType")
+		panic(msg)
+	}
+	fields := exportedFields("MapIndexed", sv, fv.Type().In(0))
+
+	ys := reflect.MakeMapWithSize(reflect.MapOf(reflect.TypeOf(""), fv.Type().Out(0)), len(fields))
+	for _, field := range fields {
+		name := reflect.ValueOf(field.Name)
+		ys.SetMapIndex(name, fv.Call([]reflect.Value{sv.FieldByIndex(field.Index), name})[0])
+	}
+	return ys.Interface()
+}
+
+func mustBeFuncShape(name string, fv reflect.Value, numIn int, numOut int) {
+	if fv.Type().NumIn() != numIn || fv.Type().NumOut() != numOut {
+		msg := fmt.Sprintf("%v: function signature %v should have %v parameters and %v results.", name, fv.Type(), numIn, numOut)
+		panic(msg)
+	}
+}
+
+func exportedFields(name string, sv reflect.Value, t reflect.Type) []reflect.StructField {
+	fields := []reflect.StructField{}
+	for i := 0; i < sv.NumField(); i++ {
+		field := sv.Type().Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		if !field.Type.AssignableTo(t) {
+			msg := fmt.Sprintf("%v: field %v's type %v should be assignable to %v.", name, field.Name, field.Type, t)
+			panic(msg)
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func KeyValueMap(f interface{}, m interface{}) interface{} {
+	fv := reflect.ValueOf(f)
+	mustBe(fv, reflect.Func)
+	if a, ok := m.(*Association); ok {
+		mustBeFuncShape("KeyValueMap", fv, 2, 1)
+		ys := reflect.MakeSlice(reflect.SliceOf(fv.Type().Out(0)), a.Len(), a.Len())
+		for i, rule := range a.Rules() {
+			ys.Index(i).Set(callAssociationFunc("KeyValueMap", fv, rule.Key, rule.Value))
+		}
+		return ys.Interface()
+	}
+
+	sv := reflect.ValueOf(m)
+	mustBeMap(sv)
+	mustBeFuncSignature(sv, fv, 1, sv.Type().Key(), sv.Type().Elem(), nil)
+
+	ys := reflect.MakeSlice(reflect.SliceOf(fv.Type().Out(0)), sv.Len(), sv.Len())
+	for i, key := range sortedMapKeys(sv) {
+		ys.Index(i).Set(fv.Call([]reflect.Value{key, sv.MapIndex(key)})[0])
+	}
+	return ys.Interface()
+}
+
+// MapAt applies f to the parts of expr at the given keys of a map or an
+// Association, or at the given positions of a slice. A position is an index,
+// negative from the end, or an []int path into nested slices.
+func MapAt(f interface{}, expr interface{}, keys ...interface{}) interface{} {
+	fv := reflect.ValueOf(f)
+	mustBe(fv, reflect.Func)
+	if a, ok := expr.(*Association); ok {
+		return mapAtAssociation(fv, a, keys)
+	}
+
+	sv := reflect.ValueOf(expr)
+	if sv.Kind() == reflect.Slice || sv.Kind() == reflect.Array {
+		mustBeFuncShape("MapAt", fv, 1, 1)
+		for _, key := range keys {
+			switch position := key.(type) {
+			case int:
+				sv = mapAtSlice(fv, sv, []int{position})
+			case []int:
+				sv = mapAtSlice(fv, sv, position)
+			default:
+				msg := fmt.Sprintf("MapAt: %v is not a position.", key)
+				panic(msg)
+			}
+		}
+		return sv.Interface()
+	}
+	mustBeMap(sv)
+	return mapAtMap(fv, sv, keys)
+}
+
+// mapAtSlice gives a copy of sv whose part at path is mapped by f.
+func mapAtSlice(fv reflect.Value, sv reflect.Value, path []int) reflect.Value {
+	if len(path) == 0 || (sv.Kind() != reflect.Slice && sv.Kind() != reflect.Array) {
+		msg := fmt.Sprintf("MapAt: position %v is out of %v.", path, sv.Interface())
+		panic(msg)
+	}
+	i := path[0]
+	if i < 0 {
+		i += sv.Len()
+	}
+	if i < 0 || i >= sv.Len() {
+		msg := fmt.Sprintf("MapAt: position %v is out of %v.", path, sv.Interface())
+		panic(msg)
+	}
+
+	ys := reflect.New(sv.Type()).Elem()
+	if sv.Kind() == reflect.Slice {
+		ys = reflect.MakeSlice(sv.Type(), sv.Len(), sv.Len())
+	}
+	reflect.Copy(ys, sv)
+	if len(path) > 1 {
+		ys.Index(i).Set(mapAtSlice(fv, sv.Index(i), path[1:]))
+		return ys
+	}
+
+	elementType := sv.Type().Elem()
+	if !verifyFuncSignature(fv, 1, elementType, nil) || !fv.Type().Out(0).AssignableTo(elementType) {
+		msg := fmt.Sprintf("MapAt: function signature must be func(%v) %v", elementType, elementType)
+		panic(msg)
+	}
+	ys.Index(i).Set(fv.Call([]reflect.Value{sv.Index(i)})[0])
+	return ys
+}
+
+func mapAtAssociation(fv reflect.Value, a *Association, keys []interface{}) *Association {
+	b := NewAssociation(a.Rules()...)
+	for _, key := range keys {
+		value, ok := b.Get(key)
+		if !ok {
+			msg := fmt.Sprintf("MapAt: key %v is missing in %v.", key, a)
+			panic(msg)
+		}
+		b.Set(key, callAssociationFunc("MapAt", fv, value).Interface())
+	}
+	return b
+}
+
+func mapAtMap(fv reflect.Value, sv reflect.Value, keys []interface{}) interface{} {
+	elementType := sv.Type().Elem()
+	if !verifyFuncSignature(fv, 1, elementType, nil) || !fv.Type().Out(0).AssignableTo(elementType) {
+		msg := fmt.Sprintf("MapAt: function signature must be func(%v) %v", elementType, elementType)
+		panic(msg)
+	}
+
+	ys := reflect.MakeMapWithSize(sv.Type(), sv.Len())
+	for _, key := range sv.MapKeys() {
+		ys.SetMapIndex(key, sv.MapIndex(key))
+	}
+	for _, key := range keys {
+		kv := reflect.ValueOf(key)
+		if !kv.IsValid() || !kv.Type().AssignableTo(sv.Type().Key()) {
+			msg := fmt.Sprintf("MapAt: %v's type should be %v", key, sv.Type().Key())
+			panic(msg)
+		}
+		value := ys.MapIndex(kv)
+		if !value.IsValid() {
+			msg := fmt.Sprintf("MapAt: key %v is missing in %v.", key, sv.Interface())
+			panic(msg)
+		}
+		ys.SetMapIndex(kv, fv.Call([]reflect.Value{value})[0])
+	}
+	return ys.Interface()
+}
+
+func GroupBy(f interface{}, slice interface{}) *Association {
+	sv := reflect.ValueOf(slice)
+	fv := reflect.ValueOf(f)
+	mustBe(fv, reflect.Func)
+	mustBeArraySlice(sv)
+
+	elementType := sv.Type().Elem()
+	mustBeFuncSignature(sv, fv, 1, elementType, nil)
+
+	groups := NewAssociation()
+	for i := 0; i < sv.Len(); i++ {
+		x := sv.Index(i)
+		key := fv.Call([]reflect.Value{x})[0].Interface()
+		group, ok := groups.Get(key)
+		if !ok {
+			group = reflect.MakeSlice(reflect.SliceOf(elementType), 0, 1).Interface()
+		}
+		groups.Set(key, reflect.Append(reflect.ValueOf(group), x).Interface())
+	}
+	return groups
+}
+
 func Identity(x interface{}) interface{} {
 	return x
 }
@@ -314,6 +821,42 @@ func Range(nums ...int) []int {
 	}
 }
 
+// Table gives the results of f. A function without parameters is called n
+// times with Table(f, n), a function of an int is called with each element of
+// Range(iterator...).
+func Table(f interface{}, iterator ...int) interface{} {
+	fv := reflect.ValueOf(f)
+	mustBe(fv, reflect.Func)
+	mustBeFuncShape("Table", fv, fv.Type().NumIn(), 1)
+
+	var args [][]reflect.Value
+	switch fv.Type().NumIn() {
+	case 0:
+		if len(iterator) != 1 || iterator[0] < 0 {
+			msg := fmt.Sprintf("Table: Table(f, n) of a function without parameters should have a count n but not %v.", iterator)
+			panic(msg)
+		}
+		args = make([][]reflect.Value, iterator[0])
+	case 1:
+		if fv.Type().In(0).Kind() != reflect.Int {
+			msg := fmt.Sprintf("Table: function signature %v should accept an int.", fv.Type())
+			panic(msg)
+		}
+		for _, i := range Range(iterator...) {
+			args = append(args, []reflect.Value{reflect.ValueOf(i).Convert(fv.Type().In(0))})
+		}
+	default:
+		msg := fmt.Sprintf("Table: function signature %v should have at most 1 parameter.", fv.Type())
+		panic(msg)
+	}
+
+	ys := reflect.MakeSlice(reflect.SliceOf(fv.Type().Out(0)), len(args), len(args))
+	for i, in := range args {
+		ys.Index(i).Set(fv.Call(in)[0])
+	}
+	return ys.Interface()
+}
+
 func _Range(imin, imax, step int) []int {
 	if step == 0 {
 		msg := fmt.Sprintf("Range: Range specification in Range[%v,%v,%v] does not have appropriate bounds.", imin, imax, step)
@@ -338,6 +881,9 @@ func _Range(imin, imax, step int) []int {
 }
 
 func Length(slice interface{}) int {
+	if a, ok := slice.(*Association); ok {
+		return a.Len()
+	}
 	return reflect.ValueOf(slice).Len()
 }
 
@@ -361,6 +907,22 @@ func Last(slice interface{}) interface{} {
 	return sv.Index(sv.Len() - 1).Interface()
 }
 
+// FirstOption is like First but gives None when slice is empty.
+func FirstOption(slice interface{}) Option {
+	if Length(slice) == 0 {
+		return None()
+	}
+	return Some(First(slice))
+}
+
+// LastOption is like Last but gives None when slice is empty.
+func LastOption(slice interface{}) Option {
+	if Length(slice) == 0 {
+		return None()
+	}
+	return Some(Last(slice))
+}
+
 func Take(slice interface{}, n int) interface{} {
 	if Length(slice) == 0 || n == 0 {
 		msg := fmt.Sprintf("Take: %v has zero length and no first element.", slice)
@@ -452,6 +1014,9 @@ func Drop(slice interface{}, n int) interface{} {
 }
 
 func Position(expr interface{}, pattern interface{}) [][]interface{} {
+	if a, ok := expr.(*Association); ok {
+		return positionInAssociation(a, pattern)
+	}
 	v := reflect.ValueOf(expr)
 	switch v.Kind() {
 	case reflect.Array, reflect.Slice:
@@ -488,6 +1053,9 @@ func positionInMap(sv reflect.Value, pattern interface{}) [][]interface{} {
 }
 
 func Count(expr interface{}, pattern interface{}) int {
+	if a, ok := expr.(*Association); ok {
+		return len(positionInAssociation(a, pattern))
+	}
 	v := reflect.ValueOf(expr)
 	switch v.Kind() {
 	case reflect.Slice, reflect.Array:
@@ -568,11 +1136,15 @@ func isPrimitiveComparable(x interface{}) bool {
 	case reflect.String:
 		return true
 	default:
-		return false
+		return isBigNumber(x)
 	}
 }
 
 func Greater(a interface{}, b interface{}) bool {
+	// Big numbers compare exactly with each other and with other numbers.
+	if isBigNumber(a) || isBigNumber(b) {
+		return compareNumbers("Greater", a, b) > 0
+	}
 	v1 := reflect.ValueOf(a)
 	v2 := reflect.ValueOf(b)
 	if v1.Kind() != v2.Kind() {
@@ -631,6 +1203,10 @@ func MemberQ(slice interface{}, x interface{}) bool {
 }
 
 func KeyMemberQ(m interface{}, key interface{}) bool {
+	if a, ok := m.(*Association); ok {
+		_, found := a.Get(key)
+		return found
+	}
 	sv := reflect.ValueOf(m)
 	mustBe(sv, reflect.Map)
 
@@ -645,6 +1221,9 @@ func KeyMemberQ(m interface{}, key interface{}) bool {
 }
 
 func Keys(m interface{}) interface{} {
+	if a, ok := m.(*Association); ok {
+		return a.Keys()
+	}
 	sv := reflect.ValueOf(m)
 	mustBe(sv, reflect.Map)
 
@@ -659,6 +1238,9 @@ func Keys(m interface{}) interface{} {
 }
 
 func Values(m interface{}) interface{} {
+	if a, ok := m.(*Association); ok {
+		return a.Values()
+	}
 	sv := reflect.ValueOf(m)
 	mustBe(sv, reflect.Map)
 
@@ -824,7 +1406,7 @@ func intersectFirstList(list interface{}, elementType reflect.Type, mapType refl
 	sv := reflect.ValueOf(list)
 	for j := 0; j < sv.Len(); j++ {
 		key := sv.Index(j)
-		value := mapv.MapIndex(key)
+		value := commonMap.MapIndex(key)
 		if !value.IsValid() || value.IsZero() {
 			commonMap.SetMapIndex(key, reflect.ValueOf(true))
 			commonKeys = reflect.Append(commonKeys, key)
@@ -834,12 +1416,10 @@ func intersectFirstList(list interface{}, elementType reflect.Type, mapType refl
 }
 
 func intersectRestLists(lists []interface{}, elementType reflect.Type, mapType reflect.Type, mapv reflect.Value, keys reflect.Value) (reflect.Value, reflect.Value) {
-	commonKeys := reflect.MakeSlice(reflect.SliceOf(elementType), 0, 0)
-	commonMap := reflect.MakeMap(mapType)
 	for i := 0; i < len(lists); i++ {
-		commonKeys, commonMap = intersectRestList(lists[i], elementType, mapType, mapv, keys)
+		keys, mapv = intersectRestList(lists[i], elementType, mapType, mapv, keys)
 	}
-	return commonKeys, commonMap
+	return keys, mapv
 }
 
 func intersectRestList(list interface{}, elementType reflect.Type, mapType reflect.Type, mapv reflect.Value, keys reflect.Value) (reflect.Value, reflect.Value) {
@@ -971,31 +1551,24 @@ func Complement(list1 interface{}, list2 interface{}) interface{} {
 	sv2 := reflect.ValueOf(list2)
 	mustBeArraySlice(sv1)
 	mustBeArraySlice(sv2)
-	if sv1.Type().Elem() != sv1.Type().Elem() {
+	if sv1.Type().Elem() != sv2.Type().Elem() {
 		msg := fmt.Sprintf("Complement: %v's type should as same as %v's type.", list1, list2)
 		panic(msg)
 	}
 	elementType := sv1.Type().Elem()
 
 	mapType := reflect.MapOf(elementType, reflect.TypeOf(true))
-
-	mapv1 := reflect.MakeMap(mapType)
-	mapv2 := reflect.MakeMap(mapType)
-
-	for i := 0; i < sv1.Len(); i++ {
-		mapv1.SetMapIndex(sv1.Index(i), reflect.ValueOf(true))
-	}
+	seen := reflect.MakeMap(mapType)
 	for i := 0; i < sv2.Len(); i++ {
-		mapv2.SetMapIndex(sv2.Index(i), reflect.ValueOf(true))
+		seen.SetMapIndex(sv2.Index(i), reflect.ValueOf(true))
 	}
 
-	keys := mapv1.MapKeys()
+	// Keep the order of list1, each element appears once.
 	result := reflect.MakeSlice(reflect.SliceOf(elementType), 0, 0)
-	for _, key := range keys {
-		v := mapv2.MapIndex(key) //.Convert(elementType))
-		fmt.Printf("%v\n", v)
-
-		if !v.IsValid() {
+	for i := 0; i < sv1.Len(); i++ {
+		key := sv1.Index(i)
+		if !seen.MapIndex(key).IsValid() {
+			seen.SetMapIndex(key, reflect.ValueOf(true))
 			result = reflect.Append(result, key)
 		}
 	}
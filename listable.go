@@ -0,0 +1,293 @@
+package fp
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+)
+
+// Listable gives a function which applies f to its arguments and threads
+// elementwise through the slices and nested arrays among them, the other
+// arguments are broadcast. The lists have to be of the same length at every
+// level, e.g.
+//
+//	sqrt := Listable(math.Sqrt)
+//	sqrt([][]float64{{1, 4}, {9}}) // [][]float64{{1, 2}, {3}}
+//
+// The results are slices of the result type of f.
+func Listable(f interface{}) func(args ...interface{}) interface{} {
+	fv := reflect.ValueOf(f)
+	mustBe(fv, reflect.Func)
+	t := fv.Type()
+	if t.NumOut() != 1 || t.IsVariadic() {
+		msg := fmt.Sprintf("Listable: function signature %v should have fixed parameters and 1 result.", t)
+		panic(msg)
+	}
+
+	return func(args ...interface{}) interface{} {
+		if len(args) != t.NumIn() {
+			msg := fmt.Sprintf("Listable: %v called with %v arguments; %v arguments are expected.", t, len(args), t.NumIn())
+			panic(msg)
+		}
+		return listable("Listable", func(args []interface{}) interface{} {
+			ins := make([]reflect.Value, len(args))
+			for i, arg := range args {
+				in := t.In(i)
+				if arg == nil {
+					ins[i] = reflect.Zero(in)
+					continue
+				}
+				ins[i] = reflect.ValueOf(arg)
+				if !ins[i].Type().AssignableTo(in) {
+					msg := fmt.Sprintf("Listable: %v should be of type %v but not %v.", arg, in, ins[i].Type())
+					panic(msg)
+				}
+			}
+			return fv.Call(ins)[0].Interface()
+		}, func(types []reflect.Type) reflect.Type {
+			return t.Out(0)
+		}, args...)
+	}
+}
+
+var (
+	interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+	bigIntType    = reflect.TypeOf(&big.Int{})
+	bigRatType    = reflect.TypeOf(&big.Rat{})
+	bigFloatType  = reflect.TypeOf(&big.Float{})
+)
+
+// threading applies f to args, threading it elementwise through the slices
+// and arrays among them while the other arguments are broadcast.
+//
+// The element type of a result list doesn't depend on the values: scalar gives
+// it for the element types of the arguments, and should give interface{} when
+// it isn't known from them. Only the exact numbers are promoted as a whole,
+// when an integer element overflows the list becomes a list of *big.Int, and
+// when an element isn't an integer a list of *big.Rat.
+type threading struct {
+	name   string
+	f      func(args []interface{}) interface{}
+	scalar func(types []reflect.Type) reflect.Type
+	args   []interface{}
+}
+
+// listNode holds the results of one list before they are collected into a
+// slice of their element type.
+type listNode []interface{}
+
+func listable(name string, f func(args []interface{}) interface{}, scalar func(types []reflect.Type) reflect.Type, args ...interface{}) interface{} {
+	th := &threading{name: name, f: f, scalar: scalar, args: args}
+	return th.thread(args, typesOf(args), nil)
+}
+
+// thread threads f through parts, the parts of the arguments at the position
+// path, whose static types are types.
+func (th *threading) thread(parts []interface{}, types []reflect.Type, path []int) interface{} {
+	y := th.evaluate(parts, types, path)
+	node, ok := y.(listNode)
+	if !ok {
+		return y
+	}
+	depth, scalar := 0, types
+	for hasList(scalar) {
+		depth, scalar = depth+1, elementTypes(scalar)
+	}
+	return collect(node, promote(node, th.scalar(scalar), depth), depth).Interface()
+}
+
+// evaluate gives the results of f as nested listNodes as deep as types has
+// lists. A list among parts whose static type is an interface is threaded on
+// its own.
+func (th *threading) evaluate(parts []interface{}, types []reflect.Type, path []int) interface{} {
+	n, list := -1, -1
+	for i, part := range parts {
+		if !isList(part) {
+			continue
+		}
+		if m := reflect.ValueOf(part).Len(); n >= 0 && m != n {
+			panicShapes(th.name, th.args, list, i, path)
+		}
+		n, list = reflect.ValueOf(part).Len(), i
+	}
+	if list < 0 {
+		return th.f(parts)
+	}
+	if !hasList(types) {
+		return th.thread(parts, typesOf(parts), path)
+	}
+
+	node := make(listNode, n)
+	partTypes := elementTypes(types)
+	for i := range node {
+		elements := make([]interface{}, len(parts))
+		for j, part := range parts {
+			if isList(part) {
+				elements[j] = reflect.ValueOf(part).Index(i).Interface()
+			} else {
+				elements[j] = part
+			}
+		}
+		node[i] = th.evaluate(elements, partTypes, append(path[:len(path):len(path)], i+1))
+	}
+	return node
+}
+
+// promote gives the element type of the results in node at depth, which is t
+// unless an exact number doesn't fit in it.
+func promote(node listNode, t reflect.Type, depth int) reflect.Type {
+	if t.Kind() == reflect.Interface {
+		return t
+	}
+	for _, u := range []reflect.Type{t, bigIntType, bigRatType} {
+		if u != t && !isExactType(t) {
+			break
+		}
+		if fitsIn(node, u, depth) {
+			return u
+		}
+	}
+	return interfaceType
+}
+
+func fitsIn(node listNode, t reflect.Type, depth int) bool {
+	for _, y := range node {
+		if depth > 1 {
+			if !fitsIn(y.(listNode), t, depth-1) {
+				return false
+			}
+		} else if _, ok := exactConvert(y, t); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// collect gives the results in node at depth as nested slices of t.
+func collect(node listNode, t reflect.Type, depth int) reflect.Value {
+	elementType := t
+	for i := 1; i < depth; i++ {
+		elementType = reflect.SliceOf(elementType)
+	}
+	ys := reflect.MakeSlice(reflect.SliceOf(elementType), len(node), len(node))
+	for i, y := range node {
+		if depth > 1 {
+			ys.Index(i).Set(collect(y.(listNode), t, depth-1))
+		} else {
+			v, _ := exactConvert(y, t)
+			ys.Index(i).Set(v)
+		}
+	}
+	return ys
+}
+
+// exactConvert gives x as a value of type t, ok is false unless t is an
+// interface x implements, the type of x or an exact number type which holds
+// the value of the exact number x.
+func exactConvert(x interface{}, t reflect.Type) (v reflect.Value, ok bool) {
+	switch {
+	case x == nil:
+		switch t.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Slice, reflect.Map, reflect.Func, reflect.Chan:
+			return reflect.Zero(t), true
+		}
+		return reflect.Value{}, false
+	case reflect.TypeOf(x).AssignableTo(t):
+		return reflect.ValueOf(x), true
+	case !isExactType(t) || !isExactType(reflect.TypeOf(x)):
+		return reflect.Value{}, false
+	case t == bigRatType:
+		r, _ := exactRat("Listable", x)
+		return reflect.ValueOf(r), true
+	}
+	if r, ok := x.(*big.Rat); ok {
+		if !r.IsInt() {
+			return reflect.Value{}, false
+		}
+		x = new(big.Int).Set(r.Num())
+	}
+	z := bigInteger("Listable", x)
+	if t == bigIntType {
+		return reflect.ValueOf(z), true
+	}
+	y, ok := fitInteger(z, reflect.Zero(t).Interface())
+	return reflect.ValueOf(y), ok
+}
+
+// isExactType reports whether t is an integer type, *big.Int or *big.Rat.
+func isExactType(t reflect.Type) bool {
+	if t == bigIntType || t == bigRatType {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+// typesOf gives the dynamic types of args, nil for a nil argument.
+func typesOf(args []interface{}) []reflect.Type {
+	types := make([]reflect.Type, len(args))
+	for i, arg := range args {
+		types[i] = reflect.TypeOf(arg)
+	}
+	return types
+}
+
+func hasList(types []reflect.Type) bool {
+	for _, t := range types {
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			return true
+		}
+	}
+	return false
+}
+
+// elementTypes gives the element types of the lists among types, and the other
+// types as they are.
+func elementTypes(types []reflect.Type) []reflect.Type {
+	elements := make([]reflect.Type, len(types))
+	for i, t := range types {
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elements[i] = t.Elem()
+		} else {
+			elements[i] = t
+		}
+	}
+	return elements
+}
+
+func isList(x interface{}) bool {
+	k := reflect.ValueOf(x).Kind()
+	return k == reflect.Slice || k == reflect.Array
+}
+
+func panicShapes(name string, args []interface{}, i int, j int, path []int) {
+	at := ""
+	if len(path) > 0 {
+		at = fmt.Sprintf(" at part %v", path)
+	}
+	msg := fmt.Sprintf("%v: lists of shapes %v and %v can't be combined; their lengths differ%v.", name, shape(args[i]), shape(args[j]), at)
+	panic(msg)
+}
+
+// shape gives the dimensions of the nested list x as far as it is regular.
+func shape(x interface{}) []int {
+	if !isList(x) {
+		return []int{}
+	}
+	v := reflect.ValueOf(x)
+	dims := []int{v.Len()}
+	if v.Len() == 0 {
+		return dims
+	}
+	inner := shape(v.Index(0).Interface())
+	for i := 1; i < v.Len(); i++ {
+		if !reflect.DeepEqual(shape(v.Index(i).Interface()), inner) {
+			return dims
+		}
+	}
+	return append(dims, inner...)
+}
@@ -74,7 +74,26 @@ func MinInArraySlice(expr interface{}) interface{} {
 	return r
 }
 
-func Abs( x interface{}) interface{} {
+// Abs gives the absolute value of x, it threads over lists.
+func Abs(x interface{}) interface{} {
+	return listable("Abs", func(args []interface{}) interface{} {
+		return abs(args[0])
+	}, func(types []reflect.Type) reflect.Type {
+		tier, ok := tierOfType(types[0])
+		switch {
+		case !ok:
+			return interfaceType
+		case tier == floatTier || tier == complexTier:
+			return reflect.TypeOf(float64(0))
+		}
+		return types[0]
+	}, x)
+}
+
+func abs( x interface{}) interface{} {
+	if isBigNumber(x) {
+		return absBig(x)
+	}
 	v := reflect.ValueOf(x)
 	switch v.Kind() {
 	case reflect.Int:
@@ -112,7 +131,47 @@ func Abs( x interface{}) interface{} {
 	}
 }
 var Power = Pow
-func Pow( x interface{}, y interface{}) interface{} {
+
+// Pow gives x^y, it threads over lists of the same shape, e.g. Pow(xs, 2) and
+// Pow(xs, ys). Integer powers of integers are exact, a negative one gives a
+// *big.Rat. A big exponent promotes an integer or big base to its type, e.g.
+// Pow(2, big.NewInt(3)) is a *big.Int.
+func Pow(x interface{}, y interface{}) interface{} {
+	return listable("Pow", func(args []interface{}) interface{} {
+		return pow(args[0], args[1])
+	}, func(types []reflect.Type) reflect.Type {
+		tierX, okX := tierOfType(types[0])
+		tierY, okY := tierOfType(types[1])
+		switch {
+		case !okX || !okY:
+			return interfaceType
+		case tierY > tierX && tierY <= bigFloatTier:
+			return types[1]
+		}
+		return types[0]
+	}, x, y)
+}
+
+func pow( x interface{}, y interface{}) interface{} {
+	// A big exponent promotes an exact base to its type, and is a float for a
+	// float or complex base.
+	if isBigNumber(y) {
+		if !isInteger(x) && !isBigNumber(x) {
+			return pow(x, realValue("Pow", y))
+		}
+		return powBig(promoteBase(x, y), y)
+	}
+	if isBigNumber(x) {
+		return powBig(x, y)
+	}
+	// Integer powers are exact, and promoted to *big.Int when they overflow.
+	// Negative powers are *big.Rat.
+	if isInteger(x) && isInteger(y) {
+		if realValue("Pow", y) < 0 {
+			return powBig(bigInteger("Pow", x), y)
+		}
+		return powInteger(x, y)
+	}
 	if isIntegerFloat(x) && isIntegerFloat(y) {
 		return powForNumber(x, y)
 	} else if isComplex(x) {
@@ -0,0 +1,317 @@
+package fp
+
+import (
+	"container/list"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type EvictionPolicy int
+
+const (
+	LRU EvictionPolicy = iota
+	LFU
+)
+
+type MemoizeOptions struct {
+	// MaxSize bounds the number of cached results, 0 means unbounded.
+	MaxSize int
+	Policy  EvictionPolicy
+	// TTL is how long a result stays valid, 0 means forever.
+	TTL time.Duration
+	// SingleFlight makes concurrent calls with the same arguments share one evaluation.
+	SingleFlight bool
+	Clock        Clock
+}
+
+type MemoizeStats struct {
+	Hits        uint64
+	Misses      uint64
+	Shared      uint64
+	Evictions   uint64
+	Expirations uint64
+	Size        int
+}
+
+type memoEntry struct {
+	key     string
+	outs    []reflect.Value
+	expires time.Time
+	uses    uint64
+	element *list.Element
+}
+
+type memoCall struct {
+	done     sync.WaitGroup
+	outs     []reflect.Value
+	panicked interface{}
+}
+
+// Memo caches the results of a function. It is safe for concurrent use.
+type Memo struct {
+	fv       reflect.Value
+	f        interface{}
+	opts     MemoizeOptions
+	mu       sync.Mutex
+	entries  map[string]*memoEntry
+	recency  *list.List
+	inflight map[string]*memoCall
+	stats    MemoizeStats
+}
+
+func NewMemo(f interface{}, opts ...MemoizeOptions) *Memo {
+	fv := reflect.ValueOf(f)
+	mustBe(fv, reflect.Func)
+	if len(opts) > 1 {
+		msg := fmt.Sprintf("Memoize: Memoize called with %v options; at most 1 is expected.", len(opts))
+		panic(msg)
+	}
+
+	m := &Memo{
+		fv:       fv,
+		entries:  map[string]*memoEntry{},
+		recency:  list.New(),
+		inflight: map[string]*memoCall{},
+	}
+	if len(opts) == 1 {
+		m.opts = opts[0]
+	}
+	if m.opts.Clock == nil {
+		m.opts.Clock = SystemClock
+	}
+	m.f = reflect.MakeFunc(fv.Type(), m.call).Interface()
+	return m
+}
+
+func Memoize(f interface{}, opts ...MemoizeOptions) interface{} {
+	return NewMemo(f, opts...).Func()
+}
+
+// Func gives the memoized function, it has the same type as the original one.
+func (m *Memo) Func() interface{} {
+	return m.f
+}
+
+func (m *Memo) Stats() MemoizeStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := m.stats
+	stats.Size = len(m.entries)
+	return stats
+}
+
+func (m *Memo) Purge() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = map[string]*memoEntry{}
+	m.recency.Init()
+}
+
+func (m *Memo) call(args []reflect.Value) []reflect.Value {
+	key := memoKey(args)
+
+	m.mu.Lock()
+	if outs, ok := m.lookup(key); ok {
+		m.stats.Hits++
+		m.mu.Unlock()
+		return outs
+	}
+	if c, ok := m.inflight[key]; ok {
+		m.stats.Shared++
+		m.mu.Unlock()
+		c.done.Wait()
+		if c.panicked != nil {
+			panic(c.panicked)
+		}
+		return c.outs
+	}
+	m.stats.Misses++
+	var c *memoCall
+	if m.opts.SingleFlight {
+		c = &memoCall{}
+		c.done.Add(1)
+		m.inflight[key] = c
+	}
+	m.mu.Unlock()
+
+	if c == nil {
+		outs := call(m.fv, args)
+		m.store(key, outs)
+		return outs
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			c.panicked = r
+		}
+		m.mu.Lock()
+		delete(m.inflight, key)
+		m.mu.Unlock()
+		c.done.Done()
+		if c.panicked != nil {
+			panic(c.panicked)
+		}
+	}()
+	c.outs = call(m.fv, args)
+	m.store(key, c.outs)
+	return c.outs
+}
+
+// lookup must be called with m.mu held.
+func (m *Memo) lookup(key string) ([]reflect.Value, bool) {
+	e, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if m.opts.TTL > 0 && !m.opts.Clock.Now().Before(e.expires) {
+		m.remove(e)
+		m.stats.Expirations++
+		return nil, false
+	}
+	e.uses++
+	m.recency.MoveToFront(e.element)
+	return e.outs, true
+}
+
+func (m *Memo) store(key string, outs []reflect.Value) {
+	if failed(outs) {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.entries[key]; ok {
+		m.remove(e)
+	}
+	m.expire()
+	if m.opts.MaxSize > 0 {
+		for len(m.entries) >= m.opts.MaxSize {
+			m.remove(m.victim())
+			m.stats.Evictions++
+		}
+	}
+
+	e := &memoEntry{key: key, outs: outs, uses: 1}
+	if m.opts.TTL > 0 {
+		e.expires = m.opts.Clock.Now().Add(m.opts.TTL)
+	}
+	e.element = m.recency.PushFront(e)
+	m.entries[key] = e
+}
+
+// expire removes the expired entries from the tail of the recency list, so
+// that results which are never looked up again don't pile up. It must be
+// called with m.mu held.
+func (m *Memo) expire() {
+	if m.opts.TTL <= 0 {
+		return
+	}
+	now := m.opts.Clock.Now()
+	for el := m.recency.Back(); el != nil; el = m.recency.Back() {
+		e := el.Value.(*memoEntry)
+		if now.Before(e.expires) {
+			return
+		}
+		m.remove(e)
+		m.stats.Expirations++
+	}
+}
+
+// victim chooses the entry to evict, LFU breaks ties by recency.
+func (m *Memo) victim() *memoEntry {
+	last := m.recency.Back().Value.(*memoEntry)
+	if m.opts.Policy != LFU {
+		return last
+	}
+	for el := m.recency.Back(); el != nil; el = el.Prev() {
+		if e := el.Value.(*memoEntry); e.uses < last.uses {
+			last = e
+		}
+	}
+	return last
+}
+
+func (m *Memo) remove(e *memoEntry) {
+	m.recency.Remove(e.element)
+	delete(m.entries, e.key)
+}
+
+// failed reports whether the last result is a non-nil error, such results aren't cached.
+func failed(outs []reflect.Value) bool {
+	if len(outs) == 0 {
+		return false
+	}
+	last := outs[len(outs)-1]
+	return isErrorInterface(last.Type()) && !last.IsNil()
+}
+
+// memoKey encodes arguments so that equal slices, maps and structs give equal keys.
+func memoKey(args []reflect.Value) string {
+	buf := strings.Builder{}
+	for _, arg := range args {
+		writeMemoKey(&buf, arg)
+		buf.WriteByte(';')
+	}
+	return buf.String()
+}
+
+func writeMemoKey(buf *strings.Builder, v reflect.Value) {
+	if !v.IsValid() {
+		buf.WriteString("nil")
+		return
+	}
+	buf.WriteString(v.Type().String())
+	buf.WriteByte(':')
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			buf.WriteString("nil")
+		} else {
+			writeMemoKey(buf, v.Elem())
+		}
+	case reflect.Bool:
+		buf.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buf.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		buf.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		buf.WriteString(strconv.FormatFloat(v.Float(), 'g', -1, 64))
+	case reflect.Complex64, reflect.Complex128:
+		buf.WriteString(strconv.FormatComplex(v.Complex(), 'g', -1, 128))
+	case reflect.String:
+		buf.WriteString(strconv.Quote(v.String()))
+	case reflect.Slice, reflect.Array:
+		buf.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			writeMemoKey(buf, v.Index(i))
+			buf.WriteByte(',')
+		}
+		buf.WriteByte(']')
+	case reflect.Map:
+		pairs := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			pair := strings.Builder{}
+			writeMemoKey(&pair, key)
+			pair.WriteByte('=')
+			writeMemoKey(&pair, v.MapIndex(key))
+			pairs = append(pairs, pair.String())
+		}
+		sort.Strings(pairs)
+		buf.WriteString("{" + strings.Join(pairs, ",") + "}")
+	case reflect.Struct:
+		buf.WriteByte('{')
+		for i := 0; i < v.NumField(); i++ {
+			writeMemoKey(buf, v.Field(i))
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('}')
+	default:
+		buf.WriteString(fmt.Sprintf("%#x", v.Pointer()))
+	}
+}
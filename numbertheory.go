@@ -0,0 +1,591 @@
+package fp
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+	"reflect"
+	"sort"
+)
+
+// The number theory functions accept any integer kind and *big.Int, and give
+// their results in the type of the first argument.
+
+// Prime gives the nth prime number, Prime(1) is 2.
+func Prime(n interface{}) interface{} {
+	k := smallInteger("Prime", n)
+	if k < 1 {
+		msg := fmt.Sprintf("Prime: %v should be a positive integer.", n)
+		panic(msg)
+	}
+	limit := 15
+	if k >= 6 {
+		// The nth prime is below n (ln n + ln ln n) for n >= 6.
+		x := float64(k)
+		limit = int(x*(math.Log(x)+math.Log(math.Log(x)))) + 1
+	}
+	p := 0
+	eachPrime(2, limit, func(q int) bool {
+		p, k = q, k-1
+		return k > 0
+	})
+	return integerLike("Prime", big.NewInt(int64(p)), n)
+}
+
+// PrimeQ reports whether n is a prime number. It is deterministic below 2^64,
+// above that it uses the Baillie-PSW test which has no known counterexample.
+func PrimeQ(n interface{}) bool {
+	return primeQ(bigInteger("PrimeQ", n))
+}
+
+// PrimePi gives the number of primes less than or equal to x.
+func PrimePi(x interface{}) interface{} {
+	count := int64(0)
+	eachPrime(2, smallInteger("PrimePi", x), func(int) bool {
+		count++
+		return true
+	})
+	return integerLike("PrimePi", big.NewInt(count), x)
+}
+
+// NextPrime gives the smallest prime greater than n.
+func NextPrime(n interface{}) interface{} {
+	z := bigInteger("NextPrime", n)
+	if z.Cmp(big.NewInt(2)) < 0 {
+		return integerLike("NextPrime", big.NewInt(2), n)
+	}
+	// Only odd numbers after 2 can be prime.
+	if z.Bit(0) == 0 {
+		z.Add(z, bigOne)
+	} else {
+		z.Add(z, bigTwo)
+	}
+	for !primeQ(z) {
+		z.Add(z, bigTwo)
+	}
+	return integerLike("NextPrime", z, n)
+}
+
+// Primes gives the primes up to max, or between min and max, as a list of the
+// type of max.
+func Primes(bounds ...interface{}) interface{} {
+	var lo, hi interface{}
+	switch len(bounds) {
+	case 1:
+		lo, hi = 0, bounds[0]
+	case 2:
+		lo, hi = bounds[0], bounds[1]
+	default:
+		msg := fmt.Sprintf("Primes: Primes called with %v arguments; 1 or 2 arguments are expected.", len(bounds))
+		panic(msg)
+	}
+	min, max := bigInteger("Primes", lo), smallInteger("Primes", hi)
+
+	t := reflect.TypeOf(hi)
+	ys := reflect.MakeSlice(reflect.SliceOf(t), 0, 0)
+	if min.Cmp(big.NewInt(int64(max))) > 0 {
+		return ys.Interface()
+	}
+	from := 2
+	if min.Cmp(big.NewInt(2)) > 0 {
+		from = int(min.Int64())
+	}
+	eachPrime(from, max, func(p int) bool {
+		ys = reflect.Append(ys, reflect.ValueOf(integerLike("Primes", big.NewInt(int64(p)), hi)))
+		return true
+	})
+	return ys.Interface()
+}
+
+// FactorInteger gives the prime factors of n with their exponents, as a list
+// of pairs {p, k} in increasing order of p. Negative numbers have the factor
+// {-1, 1} first, 0 and 1 give themselves as their only factor.
+func FactorInteger(n interface{}) interface{} {
+	z := bigInteger("FactorInteger", n)
+	t := reflect.TypeOf(n)
+	pair := reflect.ArrayOf(2, t)
+	ys := reflect.MakeSlice(reflect.SliceOf(pair), 0, 0)
+	add := func(p *big.Int, k int) {
+		y := reflect.New(pair).Elem()
+		y.Index(0).Set(reflect.ValueOf(integerLike("FactorInteger", p, n)))
+		y.Index(1).Set(reflect.ValueOf(integerLike("FactorInteger", big.NewInt(int64(k)), n)))
+		ys = reflect.Append(ys, y)
+	}
+
+	if z.Sign() < 0 {
+		add(big.NewInt(-1), 1)
+		z.Neg(z)
+	}
+	if z.Cmp(bigOne) <= 0 {
+		if ys.Len() == 0 {
+			add(z, 1)
+		}
+		return ys.Interface()
+	}
+	for _, f := range factorize(z) {
+		add(f.p, f.k)
+	}
+	return ys.Interface()
+}
+
+// Divisors gives the positive divisors of n in increasing order.
+func Divisors(n interface{}) interface{} {
+	z := bigInteger("Divisors", n)
+	if z.Sign() == 0 {
+		msg := fmt.Sprintf("Divisors: %v has infinitely many divisors.", n)
+		panic(msg)
+	}
+	divisors := []*big.Int{big.NewInt(1)}
+	for _, f := range factorize(z.Abs(z)) {
+		count := len(divisors)
+		q := big.NewInt(1)
+		for i := 0; i < f.k; i++ {
+			q.Mul(q, f.p)
+			for _, d := range divisors[:count] {
+				divisors = append(divisors, new(big.Int).Mul(d, q))
+			}
+		}
+	}
+	sort.Slice(divisors, func(i, j int) bool { return divisors[i].Cmp(divisors[j]) < 0 })
+
+	ys := reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(n)), len(divisors), len(divisors))
+	for i, d := range divisors {
+		ys.Index(i).Set(reflect.ValueOf(integerLike("Divisors", d, n)))
+	}
+	return ys.Interface()
+}
+
+// EulerPhi gives the number of positive integers up to n which are coprime to n.
+func EulerPhi(n interface{}) interface{} {
+	z := bigInteger("EulerPhi", n)
+	z.Abs(z)
+	if z.Sign() == 0 {
+		return integerLike("EulerPhi", z, n)
+	}
+	phi := new(big.Int).Set(z)
+	for _, f := range factorize(z) {
+		// phi(n) = n * Product (1 - 1/p)
+		phi.Quo(phi, f.p)
+		phi.Mul(phi, new(big.Int).Sub(f.p, bigOne))
+	}
+	return integerLike("EulerPhi", phi, n)
+}
+
+// MoebiusMu gives 0 when n has a squared prime factor, and otherwise 1 or -1
+// for an even or odd number of prime factors.
+func MoebiusMu(n interface{}) int {
+	z := bigInteger("MoebiusMu", n)
+	if z.Sign() == 0 {
+		return 0
+	}
+	mu := 1
+	for _, f := range factorize(z.Abs(z)) {
+		if f.k > 1 {
+			return 0
+		}
+		mu = -mu
+	}
+	return mu
+}
+
+// GCD gives the greatest common divisor of its arguments, GCD() is 0.
+func GCD(args ...interface{}) interface{} {
+	if len(args) == 0 {
+		return 0
+	}
+	g := new(big.Int)
+	for _, x := range args {
+		z := bigInteger("GCD", x)
+		g.GCD(nil, nil, g.Abs(g), z.Abs(z))
+	}
+	return integerLike("GCD", g, args[0])
+}
+
+// LCM gives the least common multiple of its arguments, LCM() is 1.
+func LCM(args ...interface{}) interface{} {
+	if len(args) == 0 {
+		return 1
+	}
+	l := big.NewInt(1)
+	for _, x := range args {
+		z := bigInteger("LCM", x)
+		z.Abs(z)
+		if z.Sign() == 0 {
+			return integerLike("LCM", z, args[0])
+		}
+		g := new(big.Int).GCD(nil, nil, l, z)
+		l.Mul(l, z.Quo(z, g))
+	}
+	return integerLike("LCM", l, args[0])
+}
+
+// ExtendedGCD gives the greatest common divisor g of a and b together with
+// the coefficients x and y such that a x + b y = g. The coefficients have the
+// type of a, or are *big.Int when a is unsigned since they can be negative.
+func ExtendedGCD(a interface{}, b interface{}) (g interface{}, x interface{}, y interface{}) {
+	za, zb := bigInteger("ExtendedGCD", a), bigInteger("ExtendedGCD", b)
+	zx, zy := new(big.Int), new(big.Int)
+	zg := new(big.Int).GCD(zx, zy, new(big.Int).Abs(za), new(big.Int).Abs(zb))
+	if za.Sign() < 0 {
+		zx.Neg(zx)
+	}
+	if zb.Sign() < 0 {
+		zy.Neg(zy)
+	}
+	g = integerLike("ExtendedGCD", zg, a)
+	switch reflect.ValueOf(a).Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return g, zx, zy
+	}
+	return g, integerLike("ExtendedGCD", zx, a), integerLike("ExtendedGCD", zy, a)
+}
+
+// PowerMod gives a^b mod m in the range [0, m). A negative b gives a power of
+// the modular inverse of a.
+func PowerMod(a interface{}, b interface{}, m interface{}) interface{} {
+	za, zb, zm := bigInteger("PowerMod", a), bigInteger("PowerMod", b), modulus("PowerMod", m)
+	if zb.Sign() < 0 {
+		za = modularInverse("PowerMod", za, zm)
+		zb.Neg(zb)
+	}
+	za.Mod(za, zm)
+	return integerLike("PowerMod", new(big.Int).Exp(za, zb, zm), a)
+}
+
+// ModularInverse gives the k in the range [0, m) with k a = 1 mod m.
+func ModularInverse(a interface{}, m interface{}) interface{} {
+	za, zm := bigInteger("ModularInverse", a), modulus("ModularInverse", m)
+	return integerLike("ModularInverse", modularInverse("ModularInverse", za, zm), a)
+}
+
+// ChineseRemainder gives the smallest non-negative r with r = remainders[i]
+// mod moduli[i] for every i. The moduli don't need to be coprime, but the
+// remainders have to be consistent.
+func ChineseRemainder(remainders interface{}, moduli interface{}) interface{} {
+	rv, mv := reflect.ValueOf(remainders), reflect.ValueOf(moduli)
+	mustBeArraySlice(rv)
+	mustBeArraySlice(mv)
+	if rv.Len() != mv.Len() || rv.Len() == 0 {
+		msg := fmt.Sprintf("ChineseRemainder: %v and %v should be non-empty lists of the same length.", remainders, moduli)
+		panic(msg)
+	}
+
+	r, m := new(big.Int), big.NewInt(1)
+	for i := 0; i < rv.Len(); i++ {
+		ri := bigInteger("ChineseRemainder", rv.Index(i).Interface())
+		mi := modulus("ChineseRemainder", mv.Index(i).Interface())
+		// Solve r + m k = ri mod mi for k.
+		x := new(big.Int)
+		g := new(big.Int).GCD(x, nil, m, mi)
+		diff := new(big.Int).Sub(ri, r)
+		if new(big.Int).Mod(diff, g).Sign() != 0 {
+			msg := fmt.Sprintf("ChineseRemainder: the remainders %v are inconsistent for the moduli %v.", remainders, moduli)
+			panic(msg)
+		}
+		step := new(big.Int).Quo(mi, g)
+		k := x.Mul(x, diff.Quo(diff, g))
+		k.Mod(k, step)
+		r.Add(r, k.Mul(k, m))
+		m.Mul(m, step)
+		r.Mod(r, m)
+	}
+	return integerLike("ChineseRemainder", r, rv.Index(0).Interface())
+}
+
+var (
+	bigOne = big.NewInt(1)
+	bigTwo = big.NewInt(2)
+)
+
+// bigInteger gives a copy of the integer x as a big.Int.
+func bigInteger(name string, x interface{}) *big.Int {
+	if z, ok := x.(*big.Int); ok && z != nil {
+		return new(big.Int).Set(z)
+	}
+	v := reflect.ValueOf(x)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return new(big.Int).SetUint64(v.Uint())
+	default:
+		msg := fmt.Sprintf("%v: %v should be an integer.", name, x)
+		panic(msg)
+	}
+}
+
+// smallInteger gives the integer x as an int, for arguments which size a
+// sieve or a list.
+func smallInteger(name string, x interface{}) int {
+	z := bigInteger(name, x)
+	if !z.IsInt64() || z.Int64() > math.MaxInt32 {
+		msg := fmt.Sprintf("%v: %v is too large.", name, x)
+		panic(msg)
+	}
+	return int(z.Int64())
+}
+
+// integerLike gives z in the type of the integer like, it panics when z
+// doesn't fit.
+func integerLike(name string, z *big.Int, like interface{}) interface{} {
+	if x, ok := fitInteger(z, like); ok {
+		return x
+	}
+	msg := fmt.Sprintf("%v: %v doesn't fit in %v.", name, z, reflect.TypeOf(like))
+	panic(msg)
+}
+
+// fitInteger gives z in the type of the integer like, ok is false when z
+// doesn't fit.
+func fitInteger(z *big.Int, like interface{}) (x interface{}, ok bool) {
+	if _, ok := like.(*big.Int); ok {
+		return z, true
+	}
+	v := reflect.New(reflect.TypeOf(like)).Elem()
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if z.IsInt64() && !v.OverflowInt(z.Int64()) {
+			v.SetInt(z.Int64())
+			return v.Interface(), true
+		}
+	default:
+		if z.IsUint64() && !v.OverflowUint(z.Uint64()) {
+			v.SetUint(z.Uint64())
+			return v.Interface(), true
+		}
+	}
+	return nil, false
+}
+
+func modulus(name string, m interface{}) *big.Int {
+	z := bigInteger(name, m)
+	if z.Sign() <= 0 {
+		msg := fmt.Sprintf("%v: the modulus %v should be positive.", name, m)
+		panic(msg)
+	}
+	return z
+}
+
+func modularInverse(name string, a *big.Int, m *big.Int) *big.Int {
+	inverse := new(big.Int).ModInverse(new(big.Int).Mod(a, m), m)
+	if inverse == nil || m.Cmp(bigOne) == 0 {
+		msg := fmt.Sprintf("%v: %v isn't invertible modulo %v.", name, a, m)
+		panic(msg)
+	}
+	return inverse
+}
+
+// sieve gives the primes up to a small n with the sieve of Eratosthenes.
+func sieve(n int) []int {
+	if n < 2 {
+		return []int{}
+	}
+	composite := make([]bool, n+1)
+	primes := []int{}
+	for i := 2; i <= n; i++ {
+		if composite[i] {
+			continue
+		}
+		primes = append(primes, i)
+		for j := i * i; j <= n; j += i {
+			composite[j] = true
+		}
+	}
+	return primes
+}
+
+// segmentSize is the length of the segments of eachPrime, it bounds the memory
+// of the sieve together with the primes up to the square root of its bound.
+const segmentSize = 1 << 16
+
+// eachPrime calls yield with the primes from from up to n in increasing order
+// until it gives false. It sieves one segment after another with the primes
+// up to the square root of n, so a large n doesn't need a sieve of its size.
+func eachPrime(from int, n int, yield func(p int) bool) {
+	if from < 2 {
+		from = 2
+	}
+	root := int(math.Sqrt(float64(n)))
+	for root*root > n {
+		root--
+	}
+	for (root+1)*(root+1) <= n {
+		root++
+	}
+	base := sieve(root)
+
+	composite := make([]bool, segmentSize)
+	for lo := from; lo <= n; lo += segmentSize {
+		hi := lo + segmentSize - 1
+		if hi > n {
+			hi = n
+		}
+		for i := range composite {
+			composite[i] = false
+		}
+		for _, p := range base {
+			if p*p > hi {
+				break
+			}
+			start := (lo + p - 1) / p * p
+			if start < p*p {
+				start = p * p
+			}
+			for j := start; j <= hi; j += p {
+				composite[j-lo] = true
+			}
+		}
+		for i := lo; i <= hi; i++ {
+			if !composite[i-lo] && !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+func primeQ(z *big.Int) bool {
+	if z.Sign() <= 0 {
+		return false
+	}
+	if z.IsUint64() {
+		return millerRabin(z.Uint64())
+	}
+	return z.ProbablyPrime(0)
+}
+
+// millerRabin is deterministic for all n < 2^64 with the first 12 primes as
+// bases.
+func millerRabin(n uint64) bool {
+	bases := []uint64{2, 3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37}
+	if n < 2 {
+		return false
+	}
+	for _, p := range bases {
+		if n%p == 0 {
+			return n == p
+		}
+	}
+
+	d, s := n-1, 0
+	for d%2 == 0 {
+		d, s = d/2, s+1
+	}
+	for _, a := range bases {
+		x := powMod64(a, d, n)
+		if x == 1 || x == n-1 {
+			continue
+		}
+		composite := true
+		for i := 1; i < s; i++ {
+			x = mulMod64(x, x, n)
+			if x == n-1 {
+				composite = false
+				break
+			}
+		}
+		if composite {
+			return false
+		}
+	}
+	return true
+}
+
+func mulMod64(a uint64, b uint64, m uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	_, r := bits.Div64(hi%m, lo, m)
+	return r
+}
+
+func powMod64(a uint64, e uint64, m uint64) uint64 {
+	r := uint64(1)
+	a %= m
+	for ; e > 0; e >>= 1 {
+		if e&1 == 1 {
+			r = mulMod64(r, a, m)
+		}
+		a = mulMod64(a, a, m)
+	}
+	return r
+}
+
+type primePower struct {
+	p *big.Int
+	k int
+}
+
+// smallPrimes are tried by division before Pollard's rho.
+var smallPrimes = sieve(1000)
+
+// factorize gives the prime factorization of n > 1 in increasing order of the
+// primes.
+func factorize(n *big.Int) []primePower {
+	n = new(big.Int).Set(n)
+	factors := []*big.Int{}
+	q, r := new(big.Int), new(big.Int)
+	for _, p := range smallPrimes {
+		bp := big.NewInt(int64(p))
+		for {
+			q.QuoRem(n, bp, r)
+			if r.Sign() != 0 {
+				break
+			}
+			factors = append(factors, bp)
+			n.Set(q)
+		}
+	}
+
+	pending := []*big.Int{}
+	if n.Cmp(bigOne) > 0 {
+		pending = append(pending, n)
+	}
+	for len(pending) > 0 {
+		m := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if primeQ(m) {
+			factors = append(factors, m)
+			continue
+		}
+		d := pollardRho(m)
+		pending = append(pending, d, new(big.Int).Quo(m, d))
+	}
+
+	sort.Slice(factors, func(i, j int) bool { return factors[i].Cmp(factors[j]) < 0 })
+	powers := []primePower{}
+	for _, p := range factors {
+		if len(powers) > 0 && powers[len(powers)-1].p.Cmp(p) == 0 {
+			powers[len(powers)-1].k++
+		} else {
+			powers = append(powers, primePower{p: p, k: 1})
+		}
+	}
+	return powers
+}
+
+// pollardRho gives a non-trivial divisor of the odd composite n, which has no
+// prime factors below 1000.
+func pollardRho(n *big.Int) *big.Int {
+	x, y, d := new(big.Int), new(big.Int), new(big.Int)
+	diff := new(big.Int)
+	for c := int64(1); ; c++ {
+		bc := big.NewInt(c)
+		f := func(z *big.Int) {
+			z.Mul(z, z)
+			z.Add(z, bc)
+			z.Mod(z, n)
+		}
+		x.SetInt64(2)
+		y.SetInt64(2)
+		d.SetInt64(1)
+		for d.Cmp(bigOne) == 0 {
+			f(x)
+			f(y)
+			f(y)
+			diff.Sub(x, y)
+			d.GCD(nil, nil, diff.Abs(diff), n)
+		}
+		if d.Cmp(n) != 0 {
+			return new(big.Int).Set(d)
+		}
+	}
+}
@@ -0,0 +1,351 @@
+package fp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrFiltered is the error of a Result rejected by Filter.
+var ErrFiltered = errors.New("fp: value was filtered out")
+
+// Option is a value which may be missing.
+type Option struct {
+	value interface{}
+	ok    bool
+}
+
+func Some(value interface{}) Option {
+	return Option{value: value, ok: true}
+}
+
+func None() Option {
+	return Option{}
+}
+
+// OptionOf converts the (T, bool) convention to an Option.
+func OptionOf(value interface{}, ok bool) Option {
+	if !ok {
+		return None()
+	}
+	return Some(value)
+}
+
+func (o Option) IsSome() bool {
+	return o.ok
+}
+
+func (o Option) IsNone() bool {
+	return !o.ok
+}
+
+func (o Option) Get() (interface{}, bool) {
+	return o.value, o.ok
+}
+
+func (o Option) Unwrap() interface{} {
+	if !o.ok {
+		panic("Option: Unwrap called on None.")
+	}
+	return o.value
+}
+
+func (o Option) UnwrapOr(defaultValue interface{}) interface{} {
+	if !o.ok {
+		return defaultValue
+	}
+	return o.value
+}
+
+func (o Option) OrElse(alternative Option) Option {
+	if !o.ok {
+		return alternative
+	}
+	return o
+}
+
+// OkOr converts the Option to a Result which fails with err when it's None.
+func (o Option) OkOr(err error) Result {
+	if !o.ok {
+		return Err(err)
+	}
+	return Ok(o.value)
+}
+
+func (o Option) Map(f interface{}) Option {
+	if !o.ok {
+		return o
+	}
+	outs := callOptionFunc("Option.Map", f, o.value)
+	if len(outs) != 1 {
+		msg := fmt.Sprintf("Option.Map: function signature %v should have 1 result.", signature(f))
+		panic(msg)
+	}
+	return Some(outs[0].Interface())
+}
+
+// FlatMap applies f, which gives an Option or (T, bool), to the value.
+func (o Option) FlatMap(f interface{}) Option {
+	if !o.ok {
+		return o
+	}
+	outs := callOptionFunc("Option.FlatMap", f, o.value)
+	switch {
+	case len(outs) == 1 && outs[0].Type() == reflect.TypeOf(Option{}):
+		return outs[0].Interface().(Option)
+	case len(outs) == 2 && outs[1].Kind() == reflect.Bool:
+		return OptionOf(outs[0].Interface(), outs[1].Bool())
+	default:
+		msg := fmt.Sprintf("Option.FlatMap: function signature %v should give Option or (T, bool).", signature(f))
+		panic(msg)
+	}
+}
+
+func (o Option) Bind(f interface{}) Option {
+	return o.FlatMap(f)
+}
+
+func (o Option) Filter(f interface{}) Option {
+	if !o.ok {
+		return o
+	}
+	outs := callOptionFunc("Option.Filter", f, o.value)
+	if len(outs) != 1 || outs[0].Kind() != reflect.Bool {
+		msg := fmt.Sprintf("Option.Filter: function signature %v should give bool.", signature(f))
+		panic(msg)
+	}
+	if !outs[0].Bool() {
+		return None()
+	}
+	return o
+}
+
+func (o Option) String() string {
+	if !o.ok {
+		return "None"
+	}
+	return fmt.Sprintf("Some(%v)", o.value)
+}
+
+// MarshalJSON encodes None as null and Some as its value.
+func (o Option) MarshalJSON() ([]byte, error) {
+	if !o.ok {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.value)
+}
+
+// UnmarshalJSON decodes null as None. When o holds a value, other JSON is
+// decoded into a value of the same type, otherwise into an interface{}.
+func (o *Option) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*o = None()
+		return nil
+	}
+	value, err := decodeLike(o.value, data)
+	if err != nil {
+		return err
+	}
+	*o = Some(value)
+	return nil
+}
+
+// Result is either a value or an error.
+type Result struct {
+	value interface{}
+	err   error
+}
+
+func Ok(value interface{}) Result {
+	return Result{value: value}
+}
+
+func Err(err error) Result {
+	if err == nil {
+		panic("Result: Err called with a nil error.")
+	}
+	return Result{err: err}
+}
+
+// ResultOf converts the (T, error) convention to a Result.
+func ResultOf(value interface{}, err error) Result {
+	if err != nil {
+		return Err(err)
+	}
+	return Ok(value)
+}
+
+func (r Result) IsOk() bool {
+	return r.err == nil
+}
+
+func (r Result) IsErr() bool {
+	return r.err != nil
+}
+
+func (r Result) Get() (interface{}, error) {
+	return r.value, r.err
+}
+
+func (r Result) Err() error {
+	return r.err
+}
+
+func (r Result) Unwrap() interface{} {
+	if r.err != nil {
+		msg := fmt.Sprintf("Result: Unwrap called on an error: %v.", r.err)
+		panic(msg)
+	}
+	return r.value
+}
+
+func (r Result) UnwrapOr(defaultValue interface{}) interface{} {
+	if r.err != nil {
+		return defaultValue
+	}
+	return r.value
+}
+
+func (r Result) OrElse(alternative Result) Result {
+	if r.err != nil {
+		return alternative
+	}
+	return r
+}
+
+func (r Result) Option() Option {
+	return OptionOf(r.value, r.err == nil)
+}
+
+// Map applies f, which gives T or (T, error), to the value.
+func (r Result) Map(f interface{}) Result {
+	if r.err != nil {
+		return r
+	}
+	outs := callOptionFunc("Result.Map", f, r.value)
+	switch {
+	case len(outs) == 1:
+		return Ok(outs[0].Interface())
+	case len(outs) == 2 && isErrorInterface(outs[1].Type()):
+		return resultOfValues(outs)
+	default:
+		msg := fmt.Sprintf("Result.Map: function signature %v should give T or (T, error).", signature(f))
+		panic(msg)
+	}
+}
+
+// FlatMap applies f, which gives a Result or (T, error), to the value.
+func (r Result) FlatMap(f interface{}) Result {
+	if r.err != nil {
+		return r
+	}
+	outs := callOptionFunc("Result.FlatMap", f, r.value)
+	switch {
+	case len(outs) == 1 && outs[0].Type() == reflect.TypeOf(Result{}):
+		return outs[0].Interface().(Result)
+	case len(outs) == 2 && isErrorInterface(outs[1].Type()):
+		return resultOfValues(outs)
+	default:
+		msg := fmt.Sprintf("Result.FlatMap: function signature %v should give Result or (T, error).", signature(f))
+		panic(msg)
+	}
+}
+
+func (r Result) Bind(f interface{}) Result {
+	return r.FlatMap(f)
+}
+
+// Filter turns the Result into ErrFiltered when f gives false for the value.
+func (r Result) Filter(f interface{}) Result {
+	if r.err != nil {
+		return r
+	}
+	outs := callOptionFunc("Result.Filter", f, r.value)
+	if len(outs) != 1 || outs[0].Kind() != reflect.Bool {
+		msg := fmt.Sprintf("Result.Filter: function signature %v should give bool.", signature(f))
+		panic(msg)
+	}
+	if !outs[0].Bool() {
+		return Err(ErrFiltered)
+	}
+	return r
+}
+
+func (r Result) String() string {
+	if r.err != nil {
+		return fmt.Sprintf("Err(%v)", r.err)
+	}
+	return fmt.Sprintf("Ok(%v)", r.value)
+}
+
+type resultJSON struct {
+	Ok    json.RawMessage `json:"ok,omitempty"`
+	Error *string         `json:"error,omitempty"`
+}
+
+// MarshalJSON encodes a Result as {"ok": value} or {"error": message}.
+func (r Result) MarshalJSON() ([]byte, error) {
+	if r.err != nil {
+		message := r.err.Error()
+		return json.Marshal(resultJSON{Error: &message})
+	}
+	data, err := json.Marshal(r.value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(resultJSON{Ok: data})
+}
+
+// UnmarshalJSON decodes what MarshalJSON gives, the value is decoded like
+// Option.UnmarshalJSON.
+func (r *Result) UnmarshalJSON(data []byte) error {
+	var rj resultJSON
+	if err := json.Unmarshal(data, &rj); err != nil {
+		return err
+	}
+	if rj.Error != nil {
+		*r = Err(errors.New(*rj.Error))
+		return nil
+	}
+	if rj.Ok == nil {
+		*r = Ok(nil)
+		return nil
+	}
+	value, err := decodeLike(r.value, rj.Ok)
+	if err != nil {
+		return err
+	}
+	*r = Ok(value)
+	return nil
+}
+
+func decodeLike(sample interface{}, data []byte) (interface{}, error) {
+	if sample == nil {
+		var value interface{}
+		err := json.Unmarshal(data, &value)
+		return value, err
+	}
+	pv := reflect.New(reflect.TypeOf(sample))
+	if err := json.Unmarshal(data, pv.Interface()); err != nil {
+		return nil, err
+	}
+	return pv.Elem().Interface(), nil
+}
+
+func callOptionFunc(name string, f interface{}, x interface{}) []reflect.Value {
+	fv := reflect.ValueOf(f)
+	mustBe(fv, reflect.Func)
+	if fv.Type().NumIn() != 1 {
+		msg := fmt.Sprintf("%v: function signature %v should have 1 parameter.", name, signature(f))
+		panic(msg)
+	}
+	return fv.Call([]reflect.Value{argumentValue(name, fv.Type().In(0), x)})
+}
+
+func resultOfValues(outs []reflect.Value) Result {
+	if err := outs[1]; !err.IsNil() {
+		return Err(err.Interface().(error))
+	}
+	return Ok(outs[0].Interface())
+}
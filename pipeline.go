@@ -0,0 +1,321 @@
+package fp
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+type pipelineStage struct {
+	name    string
+	f       interface{}
+	initial interface{}
+	in      reflect.Type
+	out     reflect.Type
+}
+
+// Pipeline chains Map, Filter, Fold, GroupBy and Sort stages. The types of
+// neighbouring stages are checked when a stage is added, so a mismatch panics
+// while the pipeline is being built rather than when it runs.
+type Pipeline struct {
+	input    reflect.Type
+	stages   []pipelineStage
+	terminal bool
+}
+
+// NewPipeline creates an empty pipeline over elements of the same type as sample.
+func NewPipeline(sample interface{}) *Pipeline {
+	if sample == nil {
+		panic("NewPipeline: sample should not be nil.")
+	}
+	return &Pipeline{input: reflect.TypeOf(sample)}
+}
+
+func (p *Pipeline) elementType() reflect.Type {
+	if len(p.stages) == 0 {
+		return p.input
+	}
+	return p.stages[len(p.stages)-1].out
+}
+
+func (p *Pipeline) add(name string, f interface{}, initial interface{}, out reflect.Type, terminal bool) *Pipeline {
+	stages := make([]pipelineStage, len(p.stages), len(p.stages)+1)
+	copy(stages, p.stages)
+	stage := pipelineStage{name: name, f: f, initial: initial, in: p.elementType(), out: out}
+	return &Pipeline{input: p.input, stages: append(stages, stage), terminal: terminal}
+}
+
+func (p *Pipeline) mustNotBeTerminal(name string) {
+	if p.terminal {
+		last := p.stages[len(p.stages)-1]
+		msg := fmt.Sprintf("Pipeline: %v can't follow %v, which gives a single %v.", name, last.name, last.out)
+		panic(msg)
+	}
+}
+
+func (p *Pipeline) checkStage(name string, f interface{}, numOut int, types ...reflect.Type) reflect.Value {
+	p.mustNotBeTerminal(name)
+	fv := reflect.ValueOf(f)
+	if fv.Kind() != reflect.Func {
+		msg := fmt.Sprintf("Pipeline: %v stage needs a function but not %v.", name, f)
+		panic(msg)
+	}
+	if !verifyFuncSignature(fv, numOut, types...) {
+		p.panicStage(name, f)
+	}
+	return fv
+}
+
+func (p *Pipeline) panicStage(name string, f interface{}) {
+	msg := fmt.Sprintf("Pipeline: stage %v #%v %v doesn't accept %v.", name, len(p.stages)+1, signature(f), p.elementType())
+	panic(msg)
+}
+
+func (p *Pipeline) Map(f interface{}) *Pipeline {
+	fv := p.checkStage("Map", f, 1, p.elementType(), nil)
+	return p.add("Map", f, nil, fv.Type().Out(0), false)
+}
+
+func (p *Pipeline) Filter(f interface{}) *Pipeline {
+	p.checkStage("Filter", f, 1, p.elementType(), reflect.TypeOf(true))
+	return p.add("Filter", f, nil, p.elementType(), false)
+}
+
+func (p *Pipeline) Fold(f interface{}, initial interface{}) *Pipeline {
+	if initial == nil {
+		panic("Pipeline: initial value of Fold should not be nil.")
+	}
+	resultType := reflect.TypeOf(initial)
+	// As in Fold, f may give any result it can take back as its accumulator.
+	fv := p.checkStage("Fold", f, 1, resultType, p.elementType(), nil)
+	if !fv.Type().Out(0).AssignableTo(fv.Type().In(0)) {
+		p.panicStage("Fold", f)
+	}
+	return p.add("Fold", f, initial, resultType, true)
+}
+
+func (p *Pipeline) GroupBy(f interface{}) *Pipeline {
+	p.checkStage("GroupBy", f, 1, p.elementType(), nil)
+	return p.add("GroupBy", f, nil, reflect.TypeOf(&Association{}), true)
+}
+
+func (p *Pipeline) Sort(less ...interface{}) *Pipeline {
+	elementType := p.elementType()
+	switch len(less) {
+	case 0:
+		p.mustNotBeTerminal("Sort")
+		if !isPrimitiveComparable(reflect.Zero(elementType).Interface()) {
+			msg := fmt.Sprintf("Pipeline: Sort needs an ordering function for %v.", elementType)
+			panic(msg)
+		}
+		return p.add("Sort", nil, nil, elementType, false)
+	case 1:
+		p.mustNotBeTerminal("Sort")
+		t := reflect.TypeOf(less[0])
+		if t == nil || t.Kind() != reflect.Func || t.NumIn() != 2 || t.NumOut() != 1 || t.Out(0) != reflect.TypeOf(true) ||
+			!elementType.AssignableTo(t.In(0)) || !elementType.AssignableTo(t.In(1)) {
+			msg := fmt.Sprintf("Pipeline: stage Sort #%v %v doesn't accept %v.", len(p.stages)+1, signature(less[0]), elementType)
+			panic(msg)
+		}
+		return p.add("Sort", less[0], nil, elementType, false)
+	default:
+		msg := fmt.Sprintf("Pipeline: Sort called with %v ordering functions; at most 1 is expected.", len(less))
+		panic(msg)
+	}
+}
+
+func (p *Pipeline) String() string {
+	buf := strings.Builder{}
+	buf.WriteString(fmt.Sprintf("Pipeline of %v", p.input))
+	for i, stage := range p.stages {
+		buf.WriteString(fmt.Sprintf("\n  %v. %v", i+1, stage.name))
+		switch {
+		case stage.f == nil:
+			buf.WriteString("(canonical order)")
+		case stage.initial != nil:
+			buf.WriteString(fmt.Sprintf("(%v, %#v)", signature(stage.f), stage.initial))
+		default:
+			buf.WriteString(fmt.Sprintf("(%v)", signature(stage.f)))
+		}
+		buf.WriteString(fmt.Sprintf(": %v -> %v", stage.in, stage.out))
+	}
+	return buf.String()
+}
+
+// Run applies the pipeline to a slice or an array held in memory. It gives a
+// slice, or the result of the final Fold or GroupBy stage.
+func (p *Pipeline) Run(source interface{}) interface{} {
+	sv := reflect.ValueOf(source)
+	mustBeArraySlice(sv)
+	p.mustAccept(sv.Type().Elem())
+
+	ys := reflect.MakeSlice(reflect.SliceOf(p.input), sv.Len(), sv.Len())
+	reflect.Copy(ys, sv)
+	var xs interface{} = ys.Interface()
+	for _, stage := range p.stages {
+		switch stage.name {
+		case "Map":
+			xs = Map(stage.f, xs)
+		case "Filter":
+			xs = Filter(stage.f, xs)
+		case "Fold":
+			xs = Fold(stage.f, stage.initial, xs)
+		case "GroupBy":
+			xs = GroupBy(stage.f, xs)
+		case "Sort":
+			xs = sortStage(stage, xs)
+		}
+	}
+	return xs
+}
+
+// Stream applies the pipeline to a channel, a generator func() (T, bool) or a
+// slice, and gives the receive-only channel of the results. Sort stages wait
+// for their whole input before they emit anything.
+func (p *Pipeline) Stream(ctx context.Context, source interface{}) interface{} {
+	if p.terminal {
+		msg := fmt.Sprintf("Pipeline: Stream can't give a channel of a pipeline ending with %v; use Collect.", p.stages[len(p.stages)-1].name)
+		panic(msg)
+	}
+	return p.stream(ctx, p.sourceChan(ctx, source), p.stages)
+}
+
+// Collect is like Stream but waits for all results. It gives the same value
+// as Run, or the error of ctx when ctx is done first.
+func (p *Pipeline) Collect(ctx context.Context, source interface{}) (interface{}, error) {
+	stages := p.stages
+	if p.terminal {
+		stages = stages[:len(stages)-1]
+	}
+	ch := reflect.ValueOf(p.stream(ctx, p.sourceChan(ctx, source), stages))
+
+	if p.terminal && p.stages[len(p.stages)-1].name == "Fold" {
+		last := p.stages[len(p.stages)-1]
+		return FoldChan(ctx, last.f, last.initial, ch.Interface())
+	}
+
+	xs := reflect.MakeSlice(reflect.SliceOf(ch.Type().Elem()), 0, 0)
+	for {
+		x, ok := recv(ctx, ch)
+		if !ok {
+			break
+		}
+		xs = reflect.Append(xs, x)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if p.terminal {
+		return GroupBy(p.stages[len(p.stages)-1].f, xs.Interface()), nil
+	}
+	return xs.Interface(), nil
+}
+
+func (p *Pipeline) mustAccept(elementType reflect.Type) {
+	if elementType != p.input {
+		msg := fmt.Sprintf("Pipeline: source of %v can't be used for a pipeline of %v.", elementType, p.input)
+		panic(msg)
+	}
+}
+
+func (p *Pipeline) sourceChan(ctx context.Context, source interface{}) reflect.Value {
+	sv := reflect.ValueOf(source)
+	switch sv.Kind() {
+	case reflect.Chan:
+		mustBeRecvChan(sv)
+		p.mustAccept(sv.Type().Elem())
+		return sv
+	case reflect.Func:
+		t := sv.Type()
+		if t.NumIn() != 0 || t.NumOut() != 2 || t.Out(1) != reflect.TypeOf(true) {
+			msg := fmt.Sprintf("Pipeline: generator %v should be func() (%v, bool).", signature(source), p.input)
+			panic(msg)
+		}
+		p.mustAccept(t.Out(0))
+		return generatorChan(ctx, sv)
+	default:
+		mustBeArraySlice(sv)
+		p.mustAccept(sv.Type().Elem())
+		return sliceChan(ctx, sv)
+	}
+}
+
+func (p *Pipeline) stream(ctx context.Context, ch reflect.Value, stages []pipelineStage) interface{} {
+	var xs interface{} = recvOnly(ch)
+	for _, stage := range stages {
+		switch stage.name {
+		case "Map":
+			xs = MapChan(ctx, stage.f, xs)
+		case "Filter":
+			xs = FilterChan(ctx, stage.f, xs)
+		case "Sort":
+			xs = sortChan(ctx, stage, xs)
+		}
+	}
+	return xs
+}
+
+func sortStage(stage pipelineStage, xs interface{}) interface{} {
+	if stage.f == nil {
+		return Sort(xs)
+	}
+	fv := reflect.ValueOf(stage.f)
+	less := func(a, b interface{}) bool {
+		return fv.Call([]reflect.Value{reflect.ValueOf(a), reflect.ValueOf(b)})[0].Bool()
+	}
+	return Sort(xs, less)
+}
+
+func sortChan(ctx context.Context, stage pipelineStage, in interface{}) interface{} {
+	iv := reflect.ValueOf(in)
+	out := makeChan(iv.Type().Elem(), 0)
+	go func() {
+		defer out.Close()
+		xs := reflect.MakeSlice(reflect.SliceOf(iv.Type().Elem()), 0, 0)
+		for {
+			x, ok := recv(ctx, iv)
+			if !ok {
+				break
+			}
+			xs = reflect.Append(xs, x)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		ys := reflect.ValueOf(sortStage(stage, xs.Interface()))
+		for i := 0; i < ys.Len(); i++ {
+			if !send(ctx, out, ys.Index(i)) {
+				return
+			}
+		}
+	}()
+	return recvOnly(out)
+}
+
+func sliceChan(ctx context.Context, sv reflect.Value) reflect.Value {
+	out := makeChan(sv.Type().Elem(), 0)
+	go func() {
+		defer out.Close()
+		for i := 0; i < sv.Len(); i++ {
+			if !send(ctx, out, sv.Index(i)) {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+func generatorChan(ctx context.Context, gv reflect.Value) reflect.Value {
+	out := makeChan(gv.Type().Out(0), 0)
+	go func() {
+		defer out.Close()
+		for {
+			outs := gv.Call([]reflect.Value{})
+			if !outs[1].Bool() || !send(ctx, out, outs[0]) {
+				return
+			}
+		}
+	}()
+	return out
+}
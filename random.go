@@ -0,0 +1,367 @@
+package fp
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RandomState is a source of random values which is safe for concurrent use.
+// The package-level random functions use the global state set by SeedRandom.
+type RandomState struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+func NewRandomState(seed int64) *RandomState {
+	return &RandomState{rng: rand.New(rand.NewSource(seed))}
+}
+
+// CryptoRandomState draws from crypto/rand, it can't be seeded.
+func CryptoRandomState() *RandomState {
+	return &RandomState{rng: rand.New(cryptoSource{})}
+}
+
+type cryptoSource struct{}
+
+func (cryptoSource) Int63() int64 {
+	return int64(cryptoSource{}.Uint64() >> 1)
+}
+
+func (cryptoSource) Uint64() uint64 {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("CryptoRandomState: %v", err))
+	}
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+func (cryptoSource) Seed(int64) {
+	panic("CryptoRandomState: a crypto-secure source can't be seeded.")
+}
+
+var globalRandom = struct {
+	sync.RWMutex
+	state *RandomState
+}{state: NewRandomState(time.Now().UnixNano())}
+
+// SeedRandom resets the global state with seed, or with the current time
+// without seed, so the package-level random functions repeat their values.
+func SeedRandom(seed ...int64) *RandomState {
+	switch len(seed) {
+	case 0:
+		return SetRandomState(NewRandomState(time.Now().UnixNano()))
+	case 1:
+		return SetRandomState(NewRandomState(seed[0]))
+	default:
+		msg := fmt.Sprintf("SeedRandom: SeedRandom called with %v seeds; at most 1 is expected.", len(seed))
+		panic(msg)
+	}
+}
+
+// SetRandomState makes state the global state, e.g. CryptoRandomState(), and
+// gives it back.
+func SetRandomState(state *RandomState) *RandomState {
+	globalRandom.Lock()
+	defer globalRandom.Unlock()
+	globalRandom.state = state
+	return state
+}
+
+func currentRandomState() *RandomState {
+	globalRandom.RLock()
+	defer globalRandom.RUnlock()
+	return globalRandom.state
+}
+
+func (s *RandomState) do(f func(rng *rand.Rand)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f(s.rng)
+}
+
+// RandomInteger gives a random integer of the type of spec: between 0 and n
+// for an integer n, or between min and max for a pair {min, max}. With dims it
+// gives a nested list of such integers.
+func RandomInteger(spec interface{}, dims ...int) interface{} {
+	return currentRandomState().RandomInteger(spec, dims...)
+}
+
+func (s *RandomState) RandomInteger(spec interface{}, dims ...int) interface{} {
+	lo, hi := randomRange("RandomInteger", spec)
+	t := lo.Type()
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		min, max := lo.Int(), hi.Int()
+		if min > max {
+			min, max = max, min
+		}
+		span := uint64(max-min) + 1
+		return s.randomArray("RandomInteger", t, dims, func(rng *rand.Rand) reflect.Value {
+			return reflect.ValueOf(min + int64(uniformUint64(rng, span))).Convert(t)
+		})
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		min, max := lo.Uint(), hi.Uint()
+		if min > max {
+			min, max = max, min
+		}
+		span := max - min + 1
+		return s.randomArray("RandomInteger", t, dims, func(rng *rand.Rand) reflect.Value {
+			return reflect.ValueOf(min + uniformUint64(rng, span)).Convert(t)
+		})
+	default:
+		msg := fmt.Sprintf("RandomInteger: %v should be an integer or a pair of integers.", spec)
+		panic(msg)
+	}
+}
+
+// uniformUint64 gives an integer in [0, span), span 0 stands for 2^64.
+func uniformUint64(rng *rand.Rand, span uint64) uint64 {
+	if span == 0 {
+		return rng.Uint64()
+	}
+	// Reject the values above the largest multiple of span to avoid bias.
+	limit := math.MaxUint64 - math.MaxUint64%span
+	for {
+		if x := rng.Uint64(); x < limit {
+			return x % span
+		}
+	}
+}
+
+// RandomReal gives a random real in [0, 1) for a nil spec, in [0, x) for a
+// number x, or in [min, max) for a pair {min, max}. It is a float32 when spec
+// is and a float64 otherwise.
+func RandomReal(spec interface{}, dims ...int) interface{} {
+	return currentRandomState().RandomReal(spec, dims...)
+}
+
+func (s *RandomState) RandomReal(spec interface{}, dims ...int) interface{} {
+	t := reflect.TypeOf(0.0)
+	min, max := 0.0, 1.0
+	if spec != nil {
+		lo, hi := randomRange("RandomReal", spec)
+		min, max = realValue("RandomReal", lo.Interface()), realValue("RandomReal", hi.Interface())
+		if lo.Kind() == reflect.Float32 {
+			t = lo.Type()
+		}
+	}
+	return s.randomArray("RandomReal", t, dims, func(rng *rand.Rand) reflect.Value {
+		return reflect.ValueOf(min + rng.Float64()*(max-min)).Convert(t)
+	})
+}
+
+// RandomComplex gives a random complex in the unit square for a nil spec, in
+// the rectangle from 0 to z for a complex z, or in the rectangle between the
+// corners of a pair {z1, z2}.
+func RandomComplex(spec interface{}, dims ...int) interface{} {
+	return currentRandomState().RandomComplex(spec, dims...)
+}
+
+func (s *RandomState) RandomComplex(spec interface{}, dims ...int) interface{} {
+	t := reflect.TypeOf(0i)
+	min, max := complex(0, 0), complex(1, 1)
+	if spec != nil {
+		lo, hi := randomRange("RandomComplex", spec)
+		if lo.Kind() != reflect.Complex64 && lo.Kind() != reflect.Complex128 {
+			msg := fmt.Sprintf("RandomComplex: %v should be a complex number or a pair of them.", spec)
+			panic(msg)
+		}
+		min, max = lo.Complex(), hi.Complex()
+		t = lo.Type()
+	}
+	return s.randomArray("RandomComplex", t, dims, func(rng *rand.Rand) reflect.Value {
+		re := real(min) + rng.Float64()*(real(max)-real(min))
+		im := imag(min) + rng.Float64()*(imag(max)-imag(min))
+		return reflect.ValueOf(complex(re, im)).Convert(t)
+	})
+}
+
+// randomRange gives the bounds of spec, a number x stands for {0, x}.
+func randomRange(name string, spec interface{}) (reflect.Value, reflect.Value) {
+	v := reflect.ValueOf(spec)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if v.Len() != 2 {
+			msg := fmt.Sprintf("%v: %v should be a pair {min, max}.", name, spec)
+			panic(msg)
+		}
+		return v.Index(0), v.Index(1)
+	case reflect.Invalid:
+		msg := fmt.Sprintf("%v: %v should be a number or a pair {min, max}.", name, spec)
+		panic(msg)
+	default:
+		return reflect.Zero(v.Type()), v
+	}
+}
+
+// randomArray gives one value of gen without dims, or a nested list of them
+// with the dimensions dims.
+func (s *RandomState) randomArray(name string, t reflect.Type, dims []int, gen func(rng *rand.Rand) reflect.Value) interface{} {
+	for _, n := range dims {
+		if n < 0 {
+			msg := fmt.Sprintf("%v: %v should be non-negative dimensions.", name, dims)
+			panic(msg)
+		}
+	}
+	var result reflect.Value
+	s.do(func(rng *rand.Rand) {
+		result = fillArray(t, dims, func() reflect.Value { return gen(rng) })
+	})
+	return result.Interface()
+}
+
+func fillArray(t reflect.Type, dims []int, gen func() reflect.Value) reflect.Value {
+	if len(dims) == 0 {
+		return gen()
+	}
+	elementType := t
+	for range dims[1:] {
+		elementType = reflect.SliceOf(elementType)
+	}
+	ys := reflect.MakeSlice(reflect.SliceOf(elementType), dims[0], dims[0])
+	for i := 0; i < dims[0]; i++ {
+		ys.Index(i).Set(fillArray(t, dims[1:], gen))
+	}
+	return ys
+}
+
+// RandomChoice gives n elements of list chosen with replacement, with the
+// probabilities proportional to weights when they are given.
+func RandomChoice(list interface{}, n int, weights ...interface{}) interface{} {
+	return currentRandomState().RandomChoice(list, n, weights...)
+}
+
+func (s *RandomState) RandomChoice(list interface{}, n int, weights ...interface{}) interface{} {
+	sv := reflect.ValueOf(list)
+	mustBeArraySlice(sv)
+	mustNotBeEmpty("RandomChoice", list, sv, 1)
+	if n < 0 {
+		msg := fmt.Sprintf("RandomChoice: %v should be a non-negative count.", n)
+		panic(msg)
+	}
+
+	var cumulative []float64
+	switch len(weights) {
+	case 0:
+	case 1:
+		ws := realValues("RandomChoice", weights[0])
+		if len(ws) != sv.Len() {
+			msg := fmt.Sprintf("RandomChoice: the weights %v and %v should have the same length.", weights[0], list)
+			panic(msg)
+		}
+		cumulative = make([]float64, len(ws))
+		sum := kahan{}
+		for i, w := range ws {
+			if !(w >= 0) {
+				msg := fmt.Sprintf("RandomChoice: the weights %v should be non-negative.", weights[0])
+				panic(msg)
+			}
+			sum.add(w)
+			cumulative[i] = sum.value()
+		}
+		if cumulative[len(cumulative)-1] == 0 {
+			msg := fmt.Sprintf("RandomChoice: the weights %v should have a positive total.", weights[0])
+			panic(msg)
+		}
+	default:
+		msg := fmt.Sprintf("RandomChoice: RandomChoice called with %v weights; at most 1 is expected.", len(weights))
+		panic(msg)
+	}
+
+	ys := reflect.MakeSlice(reflect.SliceOf(sv.Type().Elem()), n, n)
+	s.do(func(rng *rand.Rand) {
+		for i := 0; i < n; i++ {
+			if cumulative == nil {
+				ys.Index(i).Set(sv.Index(rng.Intn(sv.Len())))
+				continue
+			}
+			u := rng.Float64() * cumulative[len(cumulative)-1]
+			j := sort.Search(len(cumulative), func(j int) bool { return cumulative[j] > u })
+			if j == len(cumulative) {
+				j = len(cumulative) - 1
+			}
+			ys.Index(i).Set(sv.Index(j))
+		}
+	})
+	return ys.Interface()
+}
+
+// RandomSample gives n elements of list chosen without replacement, or all of
+// them in a random order without n.
+func RandomSample(list interface{}, n ...int) interface{} {
+	return currentRandomState().RandomSample(list, n...)
+}
+
+func (s *RandomState) RandomSample(list interface{}, n ...int) interface{} {
+	sv := reflect.ValueOf(list)
+	mustBeArraySlice(sv)
+	take := sv.Len()
+	switch len(n) {
+	case 0:
+	case 1:
+		if n[0] < 0 || n[0] > sv.Len() {
+			msg := fmt.Sprintf("RandomSample: %v should be between 0 and the length of %v.", n[0], list)
+			panic(msg)
+		}
+		take = n[0]
+	default:
+		msg := fmt.Sprintf("RandomSample: RandomSample called with %v counts; at most 1 is expected.", len(n))
+		panic(msg)
+	}
+
+	indices := s.permutation(sv.Len(), take)
+	ys := reflect.MakeSlice(reflect.SliceOf(sv.Type().Elem()), take, take)
+	for i, j := range indices {
+		ys.Index(i).Set(sv.Index(j))
+	}
+	return ys.Interface()
+}
+
+// RandomPermutation gives a random ordering of Range(n).
+func RandomPermutation(n int) []int {
+	return currentRandomState().RandomPermutation(n)
+}
+
+func (s *RandomState) RandomPermutation(n int) []int {
+	if n < 0 {
+		msg := fmt.Sprintf("RandomPermutation: %v should be a non-negative count.", n)
+		panic(msg)
+	}
+	ys := s.permutation(n, n)
+	for i := range ys {
+		ys[i]++
+	}
+	return ys
+}
+
+// permutation gives the first k indices of a random permutation of n with a
+// partial Fisher-Yates shuffle.
+func (s *RandomState) permutation(n int, k int) []int {
+	indices := make([]int, n)
+	for i := range indices {
+		indices[i] = i
+	}
+	s.do(func(rng *rand.Rand) {
+		for i := 0; i < k; i++ {
+			j := i + rng.Intn(n-i)
+			indices[i], indices[j] = indices[j], indices[i]
+		}
+	})
+	return indices[:k]
+}
+
+// RandomVariate draws n values of dist from s.
+func (s *RandomState) RandomVariate(dist Distribution, n int) []float64 {
+	var xs []float64
+	s.do(func(rng *rand.Rand) {
+		xs = RandomVariate(dist, n, rng)
+	})
+	return xs
+}
@@ -0,0 +1,168 @@
+package fp
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+)
+
+// Exact rationals are *big.Rat values, which are always in lowest terms.
+
+// Numerator gives the numerator of the exact number x, a *big.Int for a
+// *big.Rat and x itself for an integer.
+func Numerator(x interface{}) interface{} {
+	if r, ok := x.(*big.Rat); ok {
+		return new(big.Int).Set(r.Num())
+	}
+	return mustBeExact("Numerator", x)
+}
+
+// Denominator gives the positive denominator of the exact number x, a
+// *big.Int for a *big.Rat and 1 in the type of x for an integer.
+func Denominator(x interface{}) interface{} {
+	if r, ok := x.(*big.Rat); ok {
+		return new(big.Int).Set(r.Denom())
+	}
+	return integerLike("Denominator", big.NewInt(1), mustBeExact("Denominator", x))
+}
+
+// Rationalize gives the simplest rational within tol of x, or the simplest
+// rational which rounds to the same float64 as x without tol. A tol of 0
+// gives the exact value of x.
+func Rationalize(x interface{}, tol ...float64) *big.Rat {
+	r, inf := exactRat("Rationalize", x)
+	if inf != 0 {
+		msg := fmt.Sprintf("Rationalize: %v should be finite.", x)
+		panic(msg)
+	}
+	switch len(tol) {
+	case 0:
+		var below, above float64
+		switch x := x.(type) {
+		case float64:
+			below, above = math.Nextafter(x, math.Inf(-1)), math.Nextafter(x, math.Inf(1))
+		case float32:
+			below, above = float64(math.Nextafter32(x, float32(math.Inf(-1)))), float64(math.Nextafter32(x, float32(math.Inf(1))))
+		default:
+			return new(big.Rat).Set(r)
+		}
+		// The reals between the midpoints to the neighbours of x round to x.
+		half := big.NewRat(1, 2)
+		lo := new(big.Rat).SetFloat64(below)
+		hi := new(big.Rat).SetFloat64(above)
+		lo.Mul(lo.Add(lo, r), half)
+		hi.Mul(hi.Add(hi, r), half)
+		return simplestBetween(lo, hi)
+	case 1:
+		if !(tol[0] >= 0) || math.IsInf(tol[0], 1) {
+			msg := fmt.Sprintf("Rationalize: %v should be a finite non-negative tolerance.", tol[0])
+			panic(msg)
+		}
+		d := new(big.Rat).SetFloat64(tol[0])
+		return simplestBetween(new(big.Rat).Sub(r, d), new(big.Rat).Add(r, d))
+	default:
+		msg := fmt.Sprintf("Rationalize: Rationalize called with %v tolerances; at most 1 is expected.", len(tol))
+		panic(msg)
+	}
+}
+
+// simplestBetween gives the rational with the smallest denominator in the
+// closed interval [lo, hi].
+func simplestBetween(lo *big.Rat, hi *big.Rat) *big.Rat {
+	if lo.Sign() <= 0 && hi.Sign() >= 0 {
+		return new(big.Rat)
+	}
+	if hi.Sign() < 0 {
+		r := simplestBetween(new(big.Rat).Neg(hi), new(big.Rat).Neg(lo))
+		return r.Neg(r)
+	}
+
+	n := floorRat(lo)
+	if lo.IsInt() {
+		return new(big.Rat).SetInt(n)
+	}
+	next := new(big.Int).Add(n, bigOne)
+	if new(big.Rat).SetInt(next).Cmp(hi) <= 0 {
+		return new(big.Rat).SetInt(next)
+	}
+	// lo and hi share the integer part n, recurse on the reciprocals of
+	// their fractional parts.
+	whole := new(big.Rat).SetInt(n)
+	r := simplestBetween(
+		new(big.Rat).Inv(new(big.Rat).Sub(hi, whole)),
+		new(big.Rat).Inv(new(big.Rat).Sub(lo, whole)))
+	return r.Add(whole, r.Inv(r))
+}
+
+// floorRat gives the greatest integer less than or equal to r.
+func floorRat(r *big.Rat) *big.Int {
+	return new(big.Int).Div(r.Num(), r.Denom())
+}
+
+// ContinuedFraction gives the first n terms of the continued fraction of x,
+// or all of them without n. A float is rationalized first. The terms are
+// []*big.Int for big numbers and []int otherwise.
+func ContinuedFraction(x interface{}, n ...int) interface{} {
+	limit := -1
+	switch len(n) {
+	case 0:
+	case 1:
+		if n[0] < 0 {
+			msg := fmt.Sprintf("ContinuedFraction: %v should be a non-negative number of terms.", n[0])
+			panic(msg)
+		}
+		limit = n[0]
+	default:
+		msg := fmt.Sprintf("ContinuedFraction: ContinuedFraction called with %v counts; at most 1 is expected.", len(n))
+		panic(msg)
+	}
+
+	r := Rationalize(x)
+	num, den := new(big.Int).Set(r.Num()), new(big.Int).Set(r.Denom())
+	terms := []*big.Int{}
+	for den.Sign() != 0 && len(terms) != limit {
+		q, m := new(big.Int).DivMod(num, den, new(big.Int))
+		terms = append(terms, q)
+		num, den = den, m
+	}
+
+	if isBigNumber(x) {
+		return terms
+	}
+	ys := make([]int, len(terms))
+	for i, t := range terms {
+		ys[i] = integerLike("ContinuedFraction", t, 0).(int)
+	}
+	return ys
+}
+
+// FromContinuedFraction gives the rational with the continued fraction terms.
+func FromContinuedFraction(terms interface{}) *big.Rat {
+	sv := reflect.ValueOf(terms)
+	mustBeArraySlice(sv)
+	mustNotBeEmpty("FromContinuedFraction", terms, sv, 1)
+	r := new(big.Rat).SetInt(bigInteger("FromContinuedFraction", sv.Index(sv.Len()-1).Interface()))
+	for i := sv.Len() - 2; i >= 0; i-- {
+		if r.Sign() == 0 {
+			msg := fmt.Sprintf("FromContinuedFraction: %v has a zero term.", terms)
+			panic(msg)
+		}
+		r.Inv(r)
+		r.Add(r, new(big.Rat).SetInt(bigInteger("FromContinuedFraction", sv.Index(i).Interface())))
+	}
+	return r
+}
+
+// mustBeExact panics unless x is an integer, a *big.Int or a *big.Rat.
+func mustBeExact(name string, x interface{}) interface{} {
+	switch x.(type) {
+	case *big.Int, *big.Rat:
+		return x
+	}
+	if !isInteger(x) {
+		msg := fmt.Sprintf("%v: %v should be an exact number.", name, x)
+		panic(msg)
+	}
+	return x
+}
@@ -0,0 +1,356 @@
+package fp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sync"
+	"time"
+)
+
+var (
+	ErrTimeout     = errors.New("fp: time constraint exceeded")
+	ErrCircuitOpen = errors.New("fp: circuit breaker is open")
+)
+
+type RetryPolicy struct {
+	// MaxAttempts counts the first call too, 0 means 3.
+	MaxAttempts  int
+	InitialDelay time.Duration
+	// MaxDelay caps the delay between attempts, 0 means no cap.
+	MaxDelay time.Duration
+	// Multiplier grows the delay after each attempt, 0 means 2.
+	Multiplier float64
+	// Jitter moves each delay randomly by up to this fraction of it.
+	Jitter float64
+	Rand   *rand.Rand
+	Clock  Clock
+	// RetryIf decides which errors are worth another attempt, nil means all.
+	RetryIf func(error) bool
+}
+
+// Retry gives a function of the same type as f, which calls f again while its
+// last result is an error. When f takes a context.Context first, Retry stops
+// waiting as soon as it's done.
+func Retry(f interface{}, policy RetryPolicy) interface{} {
+	fv := reflect.ValueOf(f)
+	mustGiveError("Retry", fv, nil)
+	if policy.MaxAttempts == 0 {
+		policy.MaxAttempts = 3
+	}
+	if policy.Multiplier == 0 {
+		policy.Multiplier = 2
+	}
+	if policy.Clock == nil {
+		policy.Clock = SystemClock
+	}
+	if policy.Rand == nil {
+		policy.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+
+	var mu sync.Mutex
+	jitter := func(d time.Duration) time.Duration {
+		if policy.Jitter == 0 {
+			return d
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return time.Duration(float64(d) * (1 + policy.Jitter*(2*policy.Rand.Float64()-1)))
+	}
+
+	return reflect.MakeFunc(fv.Type(), func(args []reflect.Value) []reflect.Value {
+		ctx := contextArgument(fv.Type(), args)
+		delay := policy.InitialDelay
+		for attempt := 1; ; attempt++ {
+			outs := call(fv, args)
+			err := lastError(outs)
+			if err == nil || attempt >= policy.MaxAttempts || (policy.RetryIf != nil && !policy.RetryIf(err)) {
+				return outs
+			}
+			if !wait(ctx, policy.Clock, jitter(delay)) {
+				return outs
+			}
+			delay = time.Duration(float64(delay) * policy.Multiplier)
+			if policy.MaxDelay > 0 && delay > policy.MaxDelay {
+				delay = policy.MaxDelay
+			}
+		}
+	}).Interface()
+}
+
+// TimeConstrained gives a function of the same type as f, which gives up on f
+// after d. It then gives failexpr, or zero values with ErrTimeout when the last
+// result of f is error or another interface which holds it; failexpr is
+// required otherwise. When f takes a context.Context first, the context it
+// gets is cancelled on timeout.
+func TimeConstrained(f interface{}, d time.Duration, failexpr ...interface{}) interface{} {
+	return TimeConstrainedWithClock(f, d, SystemClock, failexpr...)
+}
+
+func TimeConstrainedWithClock(f interface{}, d time.Duration, clock Clock, failexpr ...interface{}) interface{} {
+	fv := reflect.ValueOf(f)
+	mustBe(fv, reflect.Func)
+	t := fv.Type()
+
+	var fails []reflect.Value
+	switch {
+	case len(failexpr) == 0:
+		if !givesError(t, ErrTimeout) {
+			msg := fmt.Sprintf("TimeConstrained: %v should give error last, or failexpr should be given.", signature(f))
+			panic(msg)
+		}
+		fails = zeroResults(t, ErrTimeout)
+	case len(failexpr) == t.NumOut():
+		fails = make([]reflect.Value, len(failexpr))
+		for i, x := range failexpr {
+			fails[i] = argumentValue("TimeConstrained", t.Out(i), x)
+		}
+	default:
+		msg := fmt.Sprintf("TimeConstrained: %v results are given for %v.", len(failexpr), signature(f))
+		panic(msg)
+	}
+
+	type result struct {
+		outs     []reflect.Value
+		panicked interface{}
+	}
+
+	return reflect.MakeFunc(t, func(args []reflect.Value) []reflect.Value {
+		cancel := func() {}
+		if takesContext(t) {
+			ctx := contextArgument(t, args)
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			ctx, cancel = context.WithCancel(ctx)
+			args = append([]reflect.Value{reflect.ValueOf(&ctx).Elem()}, args[1:]...)
+		}
+		defer cancel()
+
+		done := make(chan result, 1)
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					done <- result{panicked: r}
+				}
+			}()
+			done <- result{outs: call(fv, args)}
+		}()
+
+		select {
+		case r := <-done:
+			if r.panicked != nil {
+				panic(r.panicked)
+			}
+			return r.outs
+		case <-clock.After(d):
+			return fails
+		}
+	}).Interface()
+}
+
+type CircuitState int
+
+const (
+	Closed CircuitState = iota
+	Open
+	HalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case Closed:
+		return "Closed"
+	case Open:
+		return "Open"
+	default:
+		return "HalfOpen"
+	}
+}
+
+type BreakerConfig struct {
+	// FailureThreshold is the number of failures in a row which opens the circuit, 0 means 5.
+	FailureThreshold int
+	// ResetTimeout is how long the circuit stays open before a trial call, 0 means a minute.
+	ResetTimeout time.Duration
+	// HalfOpenCalls is the number of trial calls allowed at once, 0 means 1.
+	HalfOpenCalls int
+	Clock         Clock
+	// IsFailure decides which errors count as failures, nil means all.
+	IsFailure func(error) bool
+}
+
+// Breaker stops calling a failing function for a while. It is safe for
+// concurrent use.
+type Breaker struct {
+	fv       reflect.Value
+	f        interface{}
+	cfg      BreakerConfig
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	trials   int
+}
+
+func NewCircuitBreaker(f interface{}, cfg BreakerConfig) *Breaker {
+	fv := reflect.ValueOf(f)
+	mustGiveError("CircuitBreaker", fv, ErrCircuitOpen)
+	if cfg.FailureThreshold == 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.ResetTimeout == 0 {
+		cfg.ResetTimeout = time.Minute
+	}
+	if cfg.HalfOpenCalls == 0 {
+		cfg.HalfOpenCalls = 1
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = SystemClock
+	}
+
+	b := &Breaker{fv: fv, cfg: cfg}
+	b.f = reflect.MakeFunc(fv.Type(), b.call).Interface()
+	return b
+}
+
+// CircuitBreaker gives a function of the same type as f, which fails fast with
+// ErrCircuitOpen while f keeps failing.
+func CircuitBreaker(f interface{}, cfg BreakerConfig) interface{} {
+	return NewCircuitBreaker(f, cfg).Func()
+}
+
+func (b *Breaker) Func() interface{} {
+	return b.f
+}
+
+func (b *Breaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refresh()
+	return b.state
+}
+
+func (b *Breaker) call(args []reflect.Value) []reflect.Value {
+	if !b.allow() {
+		return zeroResults(b.fv.Type(), ErrCircuitOpen)
+	}
+
+	succeeded := false
+	defer func() {
+		b.record(succeeded)
+	}()
+	outs := call(b.fv, args)
+	err := lastError(outs)
+	succeeded = err == nil || (b.cfg.IsFailure != nil && !b.cfg.IsFailure(err))
+	return outs
+}
+
+// refresh must be called with b.mu held.
+func (b *Breaker) refresh() {
+	if b.state == Open && !b.cfg.Clock.Now().Before(b.openedAt.Add(b.cfg.ResetTimeout)) {
+		b.state = HalfOpen
+		b.trials = 0
+	}
+}
+
+func (b *Breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refresh()
+	switch b.state {
+	case Closed:
+		return true
+	case HalfOpen:
+		if b.trials < b.cfg.HalfOpenCalls {
+			b.trials++
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Breaker) record(succeeded bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if succeeded {
+		b.state = Closed
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.state == HalfOpen || b.failures >= b.cfg.FailureThreshold {
+		b.state = Open
+		b.openedAt = b.cfg.Clock.Now()
+	}
+}
+
+// mustGiveError checks that the last result of fv is an error interface which
+// holds err, unless err is nil.
+func mustGiveError(name string, fv reflect.Value, err error) {
+	mustBe(fv, reflect.Func)
+	t := fv.Type()
+	if !givesError(t, err) {
+		msg := fmt.Sprintf("%v: the last result of %v should be error.", name, t)
+		panic(msg)
+	}
+}
+
+// givesError reports whether the last result of t is an error interface which
+// holds err, unless err is nil.
+func givesError(t reflect.Type, err error) bool {
+	if t.NumOut() == 0 || !isErrorInterface(t.Out(t.NumOut()-1)) {
+		return false
+	}
+	return err == nil || reflect.TypeOf(err).Implements(t.Out(t.NumOut()-1))
+}
+
+func call(fv reflect.Value, args []reflect.Value) []reflect.Value {
+	if fv.Type().IsVariadic() {
+		return fv.CallSlice(args)
+	}
+	return fv.Call(args)
+}
+
+func lastError(outs []reflect.Value) error {
+	if last := outs[len(outs)-1]; !last.IsNil() {
+		return last.Interface().(error)
+	}
+	return nil
+}
+
+func zeroResults(t reflect.Type, err error) []reflect.Value {
+	outs := make([]reflect.Value, t.NumOut())
+	for i := range outs {
+		outs[i] = reflect.Zero(t.Out(i))
+	}
+	if err != nil && givesError(t, err) {
+		last := reflect.New(t.Out(t.NumOut() - 1)).Elem()
+		last.Set(reflect.ValueOf(err))
+		outs[len(outs)-1] = last
+	}
+	return outs
+}
+
+func contextArgument(t reflect.Type, args []reflect.Value) context.Context {
+	if !takesContext(t) || args[0].IsNil() {
+		return nil
+	}
+	return args[0].Interface().(context.Context)
+}
+
+// wait sleeps for d and reports whether ctx is still alive.
+func wait(ctx context.Context, clock Clock, d time.Duration) bool {
+	if ctx == nil {
+		clock.Sleep(d)
+		return true
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case <-clock.After(d):
+		return true
+	}
+}
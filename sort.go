@@ -30,6 +30,9 @@ func Sort(args ...interface{}) interface{} {
 }
 
 func _Sort(expr interface{}, less interface{}) interface{} {
+	if ys, ok := sortFast(expr, less); ok {
+		return ys
+	}
 	v := reflect.ValueOf(expr)
 	switch v.Kind() {
 	case reflect.Slice, reflect.Array:
@@ -78,13 +81,20 @@ func sortArraySlice(expr interface{}, _less interface{}) interface{} {
 
 	slice := toXSlice(sv)
 
-	less := func(i, j int) bool {
+	var less func(i, j int) bool
+	if f, ok := _less.(func(interface{}, interface{}) bool); ok {
+		less = func(i, j int) bool {
+			return f(slice[i], slice[j])
+		}
+	} else {
 		v := reflect.ValueOf(slice)
 		fv := reflect.ValueOf(_less)
-		var ins [2]reflect.Value
-		ins[0] = v.Index(i)
-		ins[1] = v.Index(j)
-		return fv.Call(ins[:])[0].Interface().(bool)
+		less = func(i, j int) bool {
+			var ins [2]reflect.Value
+			ins[0] = v.Index(i)
+			ins[1] = v.Index(j)
+			return fv.Call(ins[:])[0].Bool()
+		}
 	}
 	_sortSlice(slice, less)
 
@@ -96,13 +106,19 @@ func sortArraySlice(expr interface{}, _less interface{}) interface{} {
 }
 
 func toXSlice(sv reflect.Value) XSlice {
-	var slice XSlice = XSlice{}
-	for i := 0; i < sv.Len(); i++ {
-		slice = append(slice, sv.Index(i).Interface())
+	slice := make(XSlice, sv.Len())
+	for i := range slice {
+		slice[i] = sv.Index(i).Interface()
 	}
 	return slice
 }
 
+// SortWith sorts data in place with the algorithm of Sort, so equal elements
+// end up in the same order as they would with Sort(xs, less).
+func SortWith(data SortInterface, less func(i, j int) bool) {
+	_sortSlice(data, less)
+}
+
 // Sort sorts data.
 // It makes one call to data.Len to determine n and O(n*log(n)) calls to
 // data.Less and data.Swap. The sort is not guaranteed to be stable.
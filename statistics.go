@@ -0,0 +1,575 @@
+package fp
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+)
+
+// Parameters {{a, b}, {c, d}} of Quantile, as in Mathematica. With the sorted
+// list s of length n and r = a + (n + b)q, the quantile is
+// s[⌊r⌋] + (s[⌈r⌉] - s[⌊r⌋])(c + d(r - ⌊r⌋)), with 1-based indices clipped to the list.
+var (
+	// QuantileInverseCDF gives an element of the list, it is the default.
+	QuantileInverseCDF = [2][2]float64{{0, 0}, {1, 0}}
+	// QuantileLinear interpolates linearly between the closest ranks.
+	QuantileLinear = [2][2]float64{{1, -1}, {0, 1}}
+	// QuantileHazen is the midpoint of the steps of the empirical CDF, Median uses it.
+	QuantileHazen = [2][2]float64{{0.5, 0}, {0, 1}}
+	// QuantileWeibull gives the expected value of the order statistics.
+	QuantileWeibull = [2][2]float64{{0, 1}, {0, 1}}
+	// QuantileMedianUnbiased is approximately median-unbiased for any distribution.
+	QuantileMedianUnbiased = [2][2]float64{{1.0 / 3, 1.0 / 3}, {0, 1}}
+	// QuantileNormalUnbiased is approximately unbiased for normal distributions.
+	QuantileNormalUnbiased = [2][2]float64{{3.0 / 8, 1.0 / 4}, {0, 1}}
+)
+
+// StatisticsSummary describes a list of real numbers. Total, Min and Max have
+// the element type of the list, except for a Total promoted to *big.Int on
+// overflow. The statistics which need two elements are NaN for a list of one
+// element.
+type StatisticsSummary struct {
+	Count             int
+	Total             interface{}
+	Min               interface{}
+	Max               interface{}
+	Mean              float64
+	Median            float64
+	Mode              interface{}
+	Quartiles         [3]float64
+	Variance          float64
+	StandardDeviation float64
+	Skewness          float64
+	Kurtosis          float64
+}
+
+// Total gives the sum of the elements of list in their type. Integers are
+// added exactly and a total which overflows is promoted to *big.Int, as in
+// Plus. Floats are added with compensated summation, so
+// Total([]float64{1e100, 1, -1e100}) is 1.
+func Total(list interface{}) interface{} {
+	sv := reflect.ValueOf(list)
+	mustBeArraySlice(sv)
+	elementType := sv.Type().Elem()
+	if isBigNumber(reflect.Zero(elementType).Interface()) {
+		return totalBig(sv)
+	}
+
+	result := reflect.New(elementType).Elem()
+	switch elementType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		total := integerTotal(sv)
+		if x, ok := fitInteger(total, result.Interface()); ok {
+			return x
+		}
+		return total
+	case reflect.Float32, reflect.Float64:
+		sum := kahan{}
+		for i := 0; i < sv.Len(); i++ {
+			sum.add(sv.Index(i).Float())
+		}
+		result.SetFloat(sum.value())
+	case reflect.Complex64, reflect.Complex128:
+		re, im := kahan{}, kahan{}
+		for i := 0; i < sv.Len(); i++ {
+			c := sv.Index(i).Complex()
+			re.add(real(c))
+			im.add(imag(c))
+		}
+		result.SetComplex(complex(re.value(), im.value()))
+	default:
+		msg := fmt.Sprintf("Total: %v should be a list of numbers.", list)
+		panic(msg)
+	}
+	return result.Interface()
+}
+
+// integerTotal gives the exact sum of the integers of sv, it adds in int64
+// until that overflows.
+func integerTotal(sv reflect.Value) *big.Int {
+	total, partial := new(big.Int), int64(0)
+	for i := 0; i < sv.Len(); i++ {
+		x := sv.Index(i)
+		var v int64
+		switch x.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			v = x.Int()
+		default:
+			if x.Uint() > math.MaxInt64 {
+				total.Add(total, new(big.Int).SetUint64(x.Uint()))
+				continue
+			}
+			v = int64(x.Uint())
+		}
+		if s, ok := plus.integer(partial, v); ok {
+			partial = s
+		} else {
+			total.Add(total, big.NewInt(partial))
+			partial = v
+		}
+	}
+	return total.Add(total, big.NewInt(partial))
+}
+
+// Mean gives the arithmetic mean of list, a float32 for []float32, a complex
+// for complex lists and a float64 otherwise. list may also be a Distribution.
+func Mean(list interface{}) interface{} {
+	if dist, ok := list.(Distribution); ok {
+		return dist.Mean()
+	}
+	sv := reflect.ValueOf(list)
+	mustBeArraySlice(sv)
+	mustNotBeEmpty("Mean", list, sv, 1)
+	switch sv.Type().Elem().Kind() {
+	case reflect.Complex64, reflect.Complex128:
+		total := reflect.ValueOf(Total(list)).Complex()
+		mean := reflect.New(sv.Type().Elem()).Elem()
+		mean.SetComplex(total / complex(float64(sv.Len()), 0))
+		return mean.Interface()
+	}
+	xs := realValues("Mean", list)
+	return floatLike(sv, kahanSum(xs)/float64(len(xs)))
+}
+
+// Median gives the middle element of the sorted list, or the mean of the two
+// middle elements when its length is even.
+func Median(list interface{}) interface{} {
+	if dist, ok := list.(Distribution); ok {
+		return dist.InverseCDF(0.5)
+	}
+	sv := reflect.ValueOf(list)
+	xs := sortedValues("Median", list)
+	mustNotBeEmpty("Median", list, sv, 1)
+	return floatLike(sv, quantile(xs, 0.5, QuantileHazen))
+}
+
+// Commonest gives the elements which appear most often in list, in the order
+// they first appear. With n, it gives the n commonest elements, the most
+// common first.
+func Commonest(list interface{}, n ...int) interface{} {
+	sv := reflect.ValueOf(list)
+	mustBeArraySlice(sv)
+	if len(n) > 1 {
+		msg := fmt.Sprintf("Commonest: Commonest called with %v counts; at most 1 is expected.", len(n))
+		panic(msg)
+	}
+
+	counts := map[interface{}]int{}
+	distinct := []reflect.Value{}
+	for i := 0; i < sv.Len(); i++ {
+		x := sv.Index(i)
+		key := x.Interface()
+		mustBeHashable("Commonest", key)
+		if counts[key] == 0 {
+			distinct = append(distinct, x)
+		}
+		counts[key]++
+	}
+	sort.SliceStable(distinct, func(i, j int) bool {
+		return counts[distinct[i].Interface()] > counts[distinct[j].Interface()]
+	})
+
+	take := len(distinct)
+	if len(n) == 1 {
+		if n[0] < 0 {
+			msg := fmt.Sprintf("Commonest: %v should be a non-negative count.", n[0])
+			panic(msg)
+		}
+		if n[0] < take {
+			take = n[0]
+		}
+	} else {
+		for take = 0; take < len(distinct); take++ {
+			if counts[distinct[take].Interface()] < counts[distinct[0].Interface()] {
+				break
+			}
+		}
+	}
+
+	ys := reflect.MakeSlice(reflect.SliceOf(sv.Type().Elem()), 0, take)
+	for _, x := range distinct[:take] {
+		ys = reflect.Append(ys, x)
+	}
+	return ys.Interface()
+}
+
+// Mode gives the commonest element of list, the first one to appear among ties.
+func Mode(list interface{}) interface{} {
+	sv := reflect.ValueOf(list)
+	mustBeArraySlice(sv)
+	mustNotBeEmpty("Mode", list, sv, 1)
+	return reflect.ValueOf(Commonest(list, 1)).Index(0).Interface()
+}
+
+// Variance gives the unbiased sample variance of list.
+func Variance(list interface{}) interface{} {
+	if dist, ok := list.(Distribution); ok {
+		return dist.Variance()
+	}
+	sv := reflect.ValueOf(list)
+	m := momentsOf("Variance", list, 2)
+	return floatLike(sv, m.variance())
+}
+
+// StandardDeviation gives the square root of Variance.
+func StandardDeviation(list interface{}) interface{} {
+	if dist, ok := list.(Distribution); ok {
+		return math.Sqrt(dist.Variance())
+	}
+	sv := reflect.ValueOf(list)
+	m := momentsOf("StandardDeviation", list, 2)
+	return floatLike(sv, math.Sqrt(m.variance()))
+}
+
+// Quantile gives the q-th quantile of list or of a Distribution, or a list of
+// quantiles when q is a list. The parameters default to QuantileInverseCDF, whose quantiles are
+// elements of list and keep its element type; other parameters give floats.
+func Quantile(list interface{}, q interface{}, parameters ...[2][2]float64) interface{} {
+	if dist, ok := list.(Distribution); ok {
+		return InverseCDF(dist, q)
+	}
+	sv := reflect.ValueOf(list)
+	xs := sortedValues("Quantile", list)
+	mustNotBeEmpty("Quantile", list, sv, 1)
+
+	params := QuantileInverseCDF
+	switch len(parameters) {
+	case 0:
+	case 1:
+		params = parameters[0]
+	default:
+		msg := fmt.Sprintf("Quantile: Quantile called with %v parameters; at most 1 is expected.", len(parameters))
+		panic(msg)
+	}
+
+	// Without interpolation the quantile is an element, which keeps its type.
+	exact := params[1][1] == 0 && (params[1][0] == 0 || params[1][0] == 1)
+	var elements []reflect.Value
+	if exact {
+		elements = make([]reflect.Value, sv.Len())
+		for i := range elements {
+			elements[i] = sv.Index(i)
+		}
+		sort.SliceStable(elements, func(i, j int) bool {
+			return compareNumbers("Quantile", elements[i].Interface(), elements[j].Interface()) < 0
+		})
+	}
+	one := func(p float64) reflect.Value {
+		if p < 0 || p > 1 {
+			msg := fmt.Sprintf("Quantile: %v should be between 0 and 1.", p)
+			panic(msg)
+		}
+		if exact {
+			return elements[quantileIndex(len(xs), p, params)]
+		}
+		return reflect.ValueOf(floatLike(sv, quantile(xs, p, params)))
+	}
+
+	qv := reflect.ValueOf(q)
+	if qv.Kind() != reflect.Slice && qv.Kind() != reflect.Array {
+		return one(realValue("Quantile", q)).Interface()
+	}
+	ps := realValues("Quantile", q)
+	resultType := reflect.TypeOf(floatLike(sv, 0))
+	if exact {
+		resultType = sv.Type().Elem()
+	}
+	ys := reflect.MakeSlice(reflect.SliceOf(resultType), len(ps), len(ps))
+	for i, p := range ps {
+		ys.Index(i).Set(one(p))
+	}
+	return ys.Interface()
+}
+
+// quantileIndex is the 0-based index of the element chosen by parameters
+// which don't interpolate.
+func quantileIndex(n int, q float64, params [2][2]float64) int {
+	r := params[0][0] + (float64(n)+params[0][1])*q
+	i := math.Floor(r)
+	if params[1][0] == 1 && r != i {
+		i++
+	}
+	return clipIndex(int(i), n) - 1
+}
+
+func quantile(xs []float64, q float64, params [2][2]float64) float64 {
+	n := len(xs)
+	r := params[0][0] + (float64(n)+params[0][1])*q
+	lo := math.Floor(r)
+	x0 := xs[clipIndex(int(lo), n)-1]
+	x1 := xs[clipIndex(int(math.Ceil(r)), n)-1]
+	return x0 + (x1-x0)*(params[1][0]+params[1][1]*(r-lo))
+}
+
+func clipIndex(i int, n int) int {
+	if i < 1 {
+		return 1
+	}
+	if i > n {
+		return n
+	}
+	return i
+}
+
+// Skewness gives the coefficient of skewness m3/m2^(3/2) of list, where mk
+// are its central moments.
+func Skewness(list interface{}) interface{} {
+	sv := reflect.ValueOf(list)
+	m := momentsOf("Skewness", list, 1)
+	return floatLike(sv, math.Sqrt(m.n)*m.m3/math.Pow(m.m2, 1.5))
+}
+
+// Kurtosis gives the coefficient of kurtosis m4/m2^2 of list, 3 for normally
+// distributed data.
+func Kurtosis(list interface{}) interface{} {
+	sv := reflect.ValueOf(list)
+	m := momentsOf("Kurtosis", list, 1)
+	return floatLike(sv, m.n*m.m4/(m.m2*m.m2))
+}
+
+// Covariance gives the unbiased sample covariance of list1 and list2.
+func Covariance(list1 interface{}, list2 interface{}) interface{} {
+	c := comomentsOf("Covariance", list1, list2)
+	return floatLike(reflect.ValueOf(list1), c.cxy/(c.n-1))
+}
+
+// Correlation gives the Pearson correlation coefficient of list1 and list2.
+func Correlation(list1 interface{}, list2 interface{}) interface{} {
+	c := comomentsOf("Correlation", list1, list2)
+	return floatLike(reflect.ValueOf(list1), c.cxy/math.Sqrt(c.cxx*c.cyy))
+}
+
+// MovingAverage gives the averages of r consecutive elements of list when
+// window is an int r, or their weighted averages when window is a list of weights.
+func MovingAverage(list interface{}, window interface{}) interface{} {
+	sv := reflect.ValueOf(list)
+	xs := realValues("MovingAverage", list)
+
+	var weights []float64
+	wv := reflect.ValueOf(window)
+	switch wv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if r := wv.Int(); r < 1 || r > int64(len(xs)) {
+			msg := fmt.Sprintf("MovingAverage: the window %v should be between 1 and %v.", window, len(xs))
+			panic(msg)
+		}
+		weights = make([]float64, wv.Int())
+		for i := range weights {
+			weights[i] = 1
+		}
+	default:
+		weights = realValues("MovingAverage", window)
+	}
+	if len(weights) == 0 || len(weights) > len(xs) {
+		msg := fmt.Sprintf("MovingAverage: the window %v should have between 1 and %v elements.", window, len(xs))
+		panic(msg)
+	}
+	totalWeight := kahanSum(weights)
+
+	ys := reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(floatLike(sv, 0))), len(xs)-len(weights)+1, len(xs)-len(weights)+1)
+	for i := 0; i < ys.Len(); i++ {
+		sum := kahan{}
+		for j, w := range weights {
+			sum.add(w * xs[i+j])
+		}
+		ys.Index(i).Set(reflect.ValueOf(floatLike(sv, sum.value()/totalWeight)))
+	}
+	return ys.Interface()
+}
+
+// Standardize shifts and scales list to have mean 0 and standard deviation 1.
+func Standardize(list interface{}) interface{} {
+	sv := reflect.ValueOf(list)
+	m := momentsOf("Standardize", list, 2)
+	sd := math.Sqrt(m.variance())
+	xs := realValues("Standardize", list)
+
+	ys := reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(floatLike(sv, 0))), len(xs), len(xs))
+	for i, x := range xs {
+		ys.Index(i).Set(reflect.ValueOf(floatLike(sv, (x-m.mean)/sd)))
+	}
+	return ys.Interface()
+}
+
+// Summary gives the descriptive statistics of list at once.
+func Summary(list interface{}) StatisticsSummary {
+	sv := reflect.ValueOf(list)
+	xs := sortedValues("Summary", list)
+	mustNotBeEmpty("Summary", list, sv, 1)
+	m := newMoments(xs)
+
+	summary := StatisticsSummary{
+		Count:    len(xs),
+		Total:    Total(list),
+		Min:      Min(list),
+		Max:      Max(list),
+		Mean:     kahanSum(xs) / float64(len(xs)),
+		Median:   quantile(xs, 0.5, QuantileHazen),
+		Mode:     Mode(list),
+		Variance: math.NaN(),
+		Skewness: math.Sqrt(m.n) * m.m3 / math.Pow(m.m2, 1.5),
+		Kurtosis: m.n * m.m4 / (m.m2 * m.m2),
+	}
+	for i, q := range []float64{0.25, 0.5, 0.75} {
+		summary.Quartiles[i] = quantile(xs, q, QuantileHazen)
+	}
+	if len(xs) > 1 {
+		summary.Variance = m.variance()
+	}
+	summary.StandardDeviation = math.Sqrt(summary.Variance)
+	return summary
+}
+
+// kahan adds floats with Neumaier's compensated summation.
+type kahan struct {
+	sum          float64
+	compensation float64
+}
+
+func (k *kahan) add(x float64) {
+	t := k.sum + x
+	if math.Abs(k.sum) >= math.Abs(x) {
+		k.compensation += (k.sum - t) + x
+	} else {
+		k.compensation += (x - t) + k.sum
+	}
+	k.sum = t
+}
+
+func (k *kahan) value() float64 {
+	return k.sum + k.compensation
+}
+
+func kahanSum(xs []float64) float64 {
+	sum := kahan{}
+	for _, x := range xs {
+		sum.add(x)
+	}
+	return sum.value()
+}
+
+// moments accumulates the mean and the sums of powers of deviations m2, m3 and
+// m4 in one pass, with Welford's updates extended to higher moments.
+type moments struct {
+	n, mean, m2, m3, m4 float64
+}
+
+func newMoments(xs []float64) moments {
+	m := moments{}
+	for _, x := range xs {
+		m.add(x)
+	}
+	return m
+}
+
+func (m *moments) add(x float64) {
+	n1 := m.n
+	m.n++
+	delta := x - m.mean
+	deltaN := delta / m.n
+	deltaN2 := deltaN * deltaN
+	term := delta * deltaN * n1
+	m.mean += deltaN
+	m.m4 += term*deltaN2*(m.n*m.n-3*m.n+3) + 6*deltaN2*m.m2 - 4*deltaN*m.m3
+	m.m3 += term*deltaN*(m.n-2) - 3*deltaN*m.m2
+	m.m2 += term
+}
+
+func (m moments) variance() float64 {
+	return m.m2 / (m.n - 1)
+}
+
+func momentsOf(name string, list interface{}, min int) moments {
+	sv := reflect.ValueOf(list)
+	xs := realValues(name, list)
+	mustNotBeEmpty(name, list, sv, min)
+	return newMoments(xs)
+}
+
+// comoments accumulates the co-moment of two lists along with their own
+// second moments in one pass.
+type comoments struct {
+	n, meanX, meanY, cxx, cyy, cxy float64
+}
+
+func comomentsOf(name string, list1 interface{}, list2 interface{}) comoments {
+	xs := realValues(name, list1)
+	ys := realValues(name, list2)
+	if len(xs) != len(ys) {
+		msg := fmt.Sprintf("%v: %v and %v should have the same length.", name, list1, list2)
+		panic(msg)
+	}
+	mustNotBeEmpty(name, list1, reflect.ValueOf(list1), 2)
+
+	c := comoments{}
+	for i := range xs {
+		c.n++
+		dx := xs[i] - c.meanX
+		dy := ys[i] - c.meanY
+		c.meanX += dx / c.n
+		c.meanY += dy / c.n
+		c.cxx += dx * (xs[i] - c.meanX)
+		c.cyy += dy * (ys[i] - c.meanY)
+		c.cxy += dx * (ys[i] - c.meanY)
+	}
+	return c
+}
+
+func mustNotBeEmpty(name string, list interface{}, sv reflect.Value, min int) {
+	if sv.Len() < min {
+		msg := fmt.Sprintf("%v: %v should have at least %v elements.", name, list, min)
+		panic(msg)
+	}
+}
+
+// realValues gives the elements of a list of real numbers as float64.
+func realValues(name string, list interface{}) []float64 {
+	sv := reflect.ValueOf(list)
+	if sv.Kind() != reflect.Slice && sv.Kind() != reflect.Array {
+		msg := fmt.Sprintf("%v: %v should be a list of real numbers.", name, list)
+		panic(msg)
+	}
+	if xs, ok := list.([]float64); ok {
+		return append([]float64{}, xs...)
+	}
+	xs := make([]float64, sv.Len())
+	for i := range xs {
+		xs[i] = realValue(name, sv.Index(i).Interface())
+	}
+	return xs
+}
+
+func realValue(name string, x interface{}) float64 {
+	v := reflect.ValueOf(x)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	default:
+		if isBigNumber(x) {
+			return bigFloat64(x)
+		}
+		msg := fmt.Sprintf("%v: %v should be a real number.", name, x)
+		panic(msg)
+	}
+}
+
+func sortedValues(name string, list interface{}) []float64 {
+	xs := realValues(name, list)
+	sort.Float64s(xs)
+	return xs
+}
+
+// floatLike gives x as a float32 for lists of float32 and as a float64 otherwise.
+func floatLike(sv reflect.Value, x float64) interface{} {
+	if sv.Type().Elem().Kind() == reflect.Float32 {
+		return float32(x)
+	}
+	return x
+}
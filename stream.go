@@ -0,0 +1,361 @@
+package fp
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+type ChanOrder int
+
+const (
+	Unordered ChanOrder = iota
+	Ordered
+)
+
+func mustBeRecvChan(v reflect.Value) {
+	mustBe(v, reflect.Chan)
+	if v.Type().ChanDir()&reflect.RecvDir == 0 {
+		msg := fmt.Sprintf("%v is a send-only channel.", v.Type())
+		panic(msg)
+	}
+}
+
+func makeChan(elementType reflect.Type, buffer int) reflect.Value {
+	return reflect.MakeChan(reflect.ChanOf(reflect.BothDir, elementType), buffer)
+}
+
+func recvOnly(ch reflect.Value) interface{} {
+	return ch.Convert(reflect.ChanOf(reflect.RecvDir, ch.Type().Elem())).Interface()
+}
+
+// recv waits for the next element of in, it gives false when in is closed or ctx is done.
+func recv(ctx context.Context, in reflect.Value) (reflect.Value, bool) {
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+		{Dir: reflect.SelectRecv, Chan: in},
+	}
+	chosen, x, ok := reflect.Select(cases)
+	if chosen == 0 || !ok {
+		return reflect.Value{}, false
+	}
+	return x, true
+}
+
+// send blocks until out accepts x, it gives false when ctx is done first.
+func send(ctx context.Context, out reflect.Value, x reflect.Value) bool {
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+		{Dir: reflect.SelectSend, Chan: out, Send: x},
+	}
+	chosen, _, _ := reflect.Select(cases)
+	return chosen == 1
+}
+
+func MapChan(ctx context.Context, f interface{}, in interface{}) interface{} {
+	iv := reflect.ValueOf(in)
+	fv := reflect.ValueOf(f)
+	mustBe(fv, reflect.Func)
+	mustBeRecvChan(iv)
+	mustBeFuncSignature(iv, fv, 1, iv.Type().Elem(), nil)
+
+	out := makeChan(fv.Type().Out(0), 0)
+	go func() {
+		defer out.Close()
+		for {
+			x, ok := recv(ctx, iv)
+			if !ok || !send(ctx, out, fv.Call([]reflect.Value{x})[0]) {
+				return
+			}
+		}
+	}()
+	return recvOnly(out)
+}
+
+func FilterChan(ctx context.Context, f interface{}, in interface{}) interface{} {
+	iv := reflect.ValueOf(in)
+	fv := reflect.ValueOf(f)
+	mustBe(fv, reflect.Func)
+	mustBeRecvChan(iv)
+	mustBeFuncSignature(iv, fv, 1, iv.Type().Elem(), reflect.TypeOf(true))
+
+	out := makeChan(iv.Type().Elem(), 0)
+	go func() {
+		defer out.Close()
+		for {
+			x, ok := recv(ctx, iv)
+			if !ok {
+				return
+			}
+			if fv.Call([]reflect.Value{x})[0].Interface().(bool) && !send(ctx, out, x) {
+				return
+			}
+		}
+	}()
+	return recvOnly(out)
+}
+
+func FoldChan(ctx context.Context, f interface{}, initial interface{}, in interface{}) (interface{}, error) {
+	iv := reflect.ValueOf(in)
+	fv := reflect.ValueOf(f)
+	mustBe(fv, reflect.Func)
+	mustBeRecvChan(iv)
+
+	resultType := reflect.ValueOf(initial).Type()
+	mustBeFoldSignature("FoldChan", fv, resultType, iv.Type().Elem())
+
+	var result = reflect.ValueOf(initial)
+	for {
+		x, ok := recv(ctx, iv)
+		if !ok {
+			break
+		}
+		result = fv.Call([]reflect.Value{result, x})[0]
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return result.Interface(), nil
+}
+
+// BatchChan groups elements of in into slices of at most size elements. When
+// window is positive, a batch is also emitted window after its first element
+// arrived, even if it isn't full.
+func BatchChan(ctx context.Context, in interface{}, size int, window time.Duration) interface{} {
+	iv := reflect.ValueOf(in)
+	mustBeRecvChan(iv)
+	if size < 0 || window < 0 {
+		msg := fmt.Sprintf("BatchChan: size %v and window %v should be non-negative.", size, window)
+		panic(msg)
+	}
+	if size == 0 && window == 0 {
+		msg := fmt.Sprintf("BatchChan: size %v or window %v should be positive.", size, window)
+		panic(msg)
+	}
+
+	batchType := reflect.SliceOf(iv.Type().Elem())
+	out := makeChan(batchType, 0)
+	go func() {
+		defer out.Close()
+		batch := reflect.MakeSlice(batchType, 0, size)
+		timer := time.NewTimer(window)
+		timer.Stop()
+		var expired <-chan time.Time
+
+		flush := func() bool {
+			if expired != nil && !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			expired = nil
+			if batch.Len() == 0 {
+				return true
+			}
+			full := batch
+			batch = reflect.MakeSlice(batchType, 0, size)
+			return send(ctx, out, full)
+		}
+
+		for {
+			cases := []reflect.SelectCase{
+				{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+				{Dir: reflect.SelectRecv, Chan: iv},
+				{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(expired)},
+			}
+			chosen, x, ok := reflect.Select(cases)
+			switch {
+			case chosen == 0:
+				return
+			case chosen == 1 && !ok:
+				flush()
+				return
+			case chosen == 1:
+				if batch.Len() == 0 && window > 0 {
+					timer.Reset(window)
+					expired = timer.C
+				}
+				batch = reflect.Append(batch, x)
+				if size > 0 && batch.Len() >= size && !flush() {
+					return
+				}
+			case chosen == 2:
+				if !flush() {
+					return
+				}
+			}
+		}
+	}()
+	return recvOnly(out)
+}
+
+func MergeChans(ctx context.Context, chans ...interface{}) interface{} {
+	if len(chans) == 0 {
+		msg := fmt.Sprintf("MergeChans: MergeChans called with %v channels; at least 1 channel is expected.", len(chans))
+		panic(msg)
+	}
+	elementType := reflect.ValueOf(chans[0]).Type().Elem()
+	for i := 0; i < len(chans); i++ {
+		iv := reflect.ValueOf(chans[i])
+		mustBeRecvChan(iv)
+		if iv.Type().Elem() != elementType {
+			msg := fmt.Sprintf("MergeChans: %v's type should as same as %v's type.", iv.Type(), reflect.ValueOf(chans[0]).Type())
+			panic(msg)
+		}
+	}
+
+	out := makeChan(elementType, 0)
+	var wg sync.WaitGroup
+	wg.Add(len(chans))
+	for i := 0; i < len(chans); i++ {
+		go func(iv reflect.Value) {
+			defer wg.Done()
+			for {
+				x, ok := recv(ctx, iv)
+				if !ok || !send(ctx, out, x) {
+					return
+				}
+			}
+		}(reflect.ValueOf(chans[i]))
+	}
+	go func() {
+		wg.Wait()
+		out.Close()
+	}()
+	return recvOnly(out)
+}
+
+// TeeChan copies every element of in to n channels. An element is read from
+// in only after all n channels received the previous one.
+func TeeChan(ctx context.Context, in interface{}, n int) interface{} {
+	iv := reflect.ValueOf(in)
+	mustBeRecvChan(iv)
+	if n <= 0 {
+		msg := fmt.Sprintf("TeeChan: %v should be positive.", n)
+		panic(msg)
+	}
+
+	elementType := iv.Type().Elem()
+	outs := make([]reflect.Value, n)
+	results := reflect.MakeSlice(reflect.SliceOf(reflect.ChanOf(reflect.RecvDir, elementType)), n, n)
+	for i := 0; i < n; i++ {
+		outs[i] = makeChan(elementType, 0)
+		results.Index(i).Set(reflect.ValueOf(recvOnly(outs[i])))
+	}
+
+	go func() {
+		defer func() {
+			for _, out := range outs {
+				out.Close()
+			}
+		}()
+		for {
+			x, ok := recv(ctx, iv)
+			if !ok {
+				return
+			}
+			for _, out := range outs {
+				if !send(ctx, out, x) {
+					return
+				}
+			}
+		}
+	}()
+	return results.Interface()
+}
+
+func ParallelMapChan(ctx context.Context, f interface{}, in interface{}, workers int, order ChanOrder) interface{} {
+	iv := reflect.ValueOf(in)
+	fv := reflect.ValueOf(f)
+	mustBe(fv, reflect.Func)
+	mustBeRecvChan(iv)
+	mustBeFuncSignature(iv, fv, 1, iv.Type().Elem(), nil)
+	if workers <= 0 {
+		msg := fmt.Sprintf("ParallelMapChan: workers %v should be positive.", workers)
+		panic(msg)
+	}
+
+	out := makeChan(fv.Type().Out(0), 0)
+	if order == Ordered {
+		go parallelMapChanOrdered(ctx, fv, iv, out, workers)
+	} else {
+		go parallelMapChanUnordered(ctx, fv, iv, out, workers)
+	}
+	return recvOnly(out)
+}
+
+func parallelMapChanUnordered(ctx context.Context, fv reflect.Value, iv reflect.Value, out reflect.Value, workers int) {
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	worker := func() {
+		defer wg.Done()
+		for {
+			x, ok := recv(ctx, iv)
+			if !ok || !send(ctx, out, fv.Call([]reflect.Value{x})[0]) {
+				return
+			}
+		}
+	}
+	for i := 0; i < workers; i++ {
+		go worker()
+	}
+	wg.Wait()
+	out.Close()
+}
+
+type mapJob struct {
+	x      reflect.Value
+	result chan reflect.Value
+}
+
+// parallelMapChanOrdered keeps at most workers elements in flight; results
+// are emitted in the order their inputs were received.
+func parallelMapChanOrdered(ctx context.Context, fv reflect.Value, iv reflect.Value, out reflect.Value, workers int) {
+	jobs := make(chan mapJob)
+	pending := make(chan chan reflect.Value, workers)
+
+	go func() {
+		defer close(jobs)
+		defer close(pending)
+		for {
+			x, ok := recv(ctx, iv)
+			if !ok {
+				return
+			}
+			job := mapJob{x: x, result: make(chan reflect.Value, 1)}
+			select {
+			case pending <- job.result:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- job:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for job := range jobs {
+				job.result <- fv.Call([]reflect.Value{job.x})[0]
+			}
+		}()
+	}
+
+	defer out.Close()
+	for result := range pending {
+		select {
+		case y := <-result:
+			if !send(ctx, out, y) {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
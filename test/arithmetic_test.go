@@ -0,0 +1,114 @@
+package test
+
+import (
+	. "fp"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"math"
+	"math/big"
+)
+
+var _ = Describe("arithmetic", func() {
+	Context("Plus, Subtract, Times and Divide", func() {
+		It("keeps the type of the operands.", func() {
+			Expect(Plus(1, 2)).To(Equal(3))
+			Expect(Subtract(uint8(7), uint8(2))).To(Equal(uint8(5)))
+			Expect(Times(float32(1.5), float32(2))).To(Equal(float32(3)))
+			Expect(Plus(1+2i, 1i)).To(Equal(1 + 3i))
+			Expect(Divide(1.0, 4.0)).To(Equal(0.25))
+		})
+
+		It("promotes mixed operands.", func() {
+			Expect(Plus(1, 0.5)).To(Equal(1.5))
+			Expect(Plus(1, float32(0.5))).To(Equal(float32(1.5)))
+			Expect(Times(float32(2), 0.25)).To(Equal(0.5))
+			Expect(Plus(1, 2i)).To(Equal(1 + 2i))
+			Expect(Times(float32(2), complex64(1i))).To(Equal(complex64(2i)))
+			Expect(Plus(big.NewRat(1, 2), 0.25)).To(Equal(0.75))
+			Expect(Plus(big.NewInt(1), big.NewRat(1, 2))).To(Equal(big.NewRat(3, 2)))
+			x := Plus(new(big.Float).SetPrec(100).SetInt64(1), big.NewRat(1, 3)).(*big.Float)
+			Expect(x.Prec()).To(Equal(uint(100)))
+			Expect(Divide(int64(7), 2)).To(Equal(big.NewRat(7, 2)))
+		})
+
+		It("promotes overflows to big.Int.", func() {
+			Expect(Plus(math.MaxInt64, 1)).To(Equal(new(big.Int).Add(big.NewInt(math.MaxInt64), big.NewInt(1))))
+			Expect(Times(int32(1<<16), int32(1<<16))).To(Equal(big.NewInt(1 << 32)))
+			Expect(Subtract(uint(1), uint(2))).To(Equal(big.NewInt(-1)))
+		})
+
+		It("promotes lists with an overflow to lists of big.Int as a whole.", func() {
+			max := big.NewInt(math.MaxInt64)
+			Expect(Plus([]int{math.MaxInt64, 1}, 1)).To(Equal([]*big.Int{new(big.Int).Add(max, big.NewInt(1)), big.NewInt(2)}))
+			Expect(Pow(int8(2), []int{1, 7})).To(Equal([]*big.Int{big.NewInt(2), big.NewInt(128)}))
+		})
+
+		It("threads over lists.", func() {
+			Expect(Plus([]int{1, 2}, 10)).To(Equal([]int{11, 12}))
+			Expect(Times(2, [3]float64{1, 2, 3})).To(Equal([]float64{2, 4, 6}))
+			Expect(Subtract([][]int{{1, 2}, {3, 4}}, []int{1, 2})).To(Equal([][]int{{0, 1}, {1, 2}}))
+			Expect(Divide([]int{2, 3}, 2)).To(Equal([]*big.Rat{big.NewRat(1, 1), big.NewRat(3, 2)}))
+			Expect(Plus([]int{}, 1)).To(Equal([]int{}))
+			Expect(Plus([]int{}, 1.5)).To(Equal([]float64{}))
+			Expect(Sign([]float64{-2, 3})).To(Equal([]int{-1, 1}))
+			Expect(Round([]*big.Rat{big.NewRat(3, 2)})).To(Equal([]*big.Int{big.NewInt(2)}))
+			Ω(func() { Plus([]int{1, 2}, []int{1, 2, 3}) }).Should(Panic())
+		})
+
+		It("are combinators.", func() {
+			Expect(Fold(Plus, 0, []int{1, 2, 3})).To(Equal(6))
+			Expect(Fold(Times, 1.0, []int{2, 3})).To(Equal(6.0))
+			Expect(Fold(Plus, big.NewRat(0, 1), []*big.Rat{big.NewRat(1, 3), big.NewRat(2, 3)})).To(Equal(big.NewRat(1, 1)))
+			Expect(Map(func(x interface{}) interface{} { return Times(x, x) }, []int{1, 2})).To(Equal([]interface{}{1, 4}))
+			Ω(func() { Fold(func(r, x int) interface{} { return r + x }, 0, []int{1}) }).Should(Panic())
+		})
+
+		It("panics on division by zero and non-numbers.", func() {
+			Ω(func() { Divide(1, 0) }).Should(Panic())
+			Ω(func() { Divide(big.NewRat(1, 2), big.NewInt(0)) }).Should(Panic())
+			Ω(func() { Plus("a", 1) }).Should(Panic())
+			Expect(math.IsInf(Divide(1.0, 0.0).(float64), 1)).To(BeTrue())
+		})
+	})
+
+	Context("Mod(x, y) and Quotient(x, y)", func() {
+		It("floors the quotient.", func() {
+			Expect(Mod(7, 3)).To(Equal(1))
+			Expect(Mod(-7, 3)).To(Equal(2))
+			Expect(Mod(7, -3)).To(Equal(-2))
+			Expect(Mod(5.5, 2)).To(Equal(1.5))
+			Expect(Mod(-0.5, 2.0)).To(Equal(1.5))
+			Expect(Mod(big.NewRat(7, 2), 1)).To(Equal(big.NewRat(1, 2)))
+			Expect(Quotient(-7, 2)).To(Equal(-4))
+			Expect(Quotient(big.NewRat(7, 2), 1)).To(Equal(big.NewInt(3)))
+			Expect(Quotient(7.5, 2)).To(Equal(3.0))
+			Expect(Mod([]int{5, 6}, 4)).To(Equal([]int{1, 2}))
+			Ω(func() { Mod(1, 0) }).Should(Panic())
+			Ω(func() { Mod(1i, 2) }).Should(Panic())
+		})
+	})
+
+	Context("Sign, Round, Floor and Ceiling", func() {
+		It("gives the sign.", func() {
+			Expect(Sign(-3)).To(Equal(-1))
+			Expect(Sign(0.0)).To(Equal(0))
+			Expect(Sign(big.NewRat(1, 3))).To(Equal(1))
+			Expect(Sign(3 + 4i)).To(Equal(0.6 + 0.8i))
+			Expect(Sign([]float64{-2, 2})).To(Equal([]int{-1, 1}))
+		})
+
+		It("rounds to integers.", func() {
+			Expect(Round([]float64{0.5, 1.5, -2.5, 2.6})).To(Equal([]float64{0, 2, -2, 3}))
+			Expect(Round(big.NewRat(5, 2))).To(Equal(big.NewInt(2)))
+			Expect(Round(big.NewRat(-3, 2))).To(Equal(big.NewInt(-2)))
+			Expect(Round(big.NewRat(7, 3))).To(Equal(big.NewInt(2)))
+			Expect(Round(float32(2.5))).To(Equal(float32(2)))
+			Expect(Round(1.5 - 2.5i)).To(Equal(2 - 2i))
+			Expect(Floor([]float64{-1.5, 1.5})).To(Equal([]float64{-2, 1}))
+			Expect(Floor(big.NewRat(-1, 2))).To(Equal(big.NewInt(-1)))
+			Expect(Ceiling(big.NewRat(-1, 2)).(*big.Int).Sign()).To(Equal(0))
+			Expect(Ceiling(1.2)).To(Equal(2.0))
+			Expect(Floor(7)).To(Equal(7))
+		})
+	})
+})
@@ -0,0 +1,163 @@
+package test
+
+import (
+	. "fp"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"strings"
+)
+
+var _ = Describe("association", func() {
+	newScores := func() *Association {
+		return NewAssociation(
+			Rule{Key: "c", Value: 3},
+			Rule{Key: "a", Value: 1},
+			Rule{Key: "b", Value: 2},
+		)
+	}
+
+	Context("NewAssociation(rules...)", func() {
+		It("keeps keys in insertion order.", func() {
+			a := newScores()
+			Expect(a.Keys()).To(Equal([]interface{}{"c", "a", "b"}))
+			Expect(a.Values()).To(Equal([]interface{}{3, 1, 2}))
+			Expect(a.Len()).To(Equal(3))
+		})
+
+		It("overwrites a value without moving its key.", func() {
+			a := newScores()
+			a.Set("a", 10)
+			Expect(a.Keys()).To(Equal([]interface{}{"c", "a", "b"}))
+			Expect(a.Values()).To(Equal([]interface{}{3, 10, 2}))
+		})
+
+		It("deletes a key.", func() {
+			a := newScores()
+			a.Delete("a")
+			Expect(a.Keys()).To(Equal([]interface{}{"c", "b"}))
+			Expect(a.String()).To(Equal("<|c -> 3, b -> 2|>"))
+		})
+
+		It("rejects keys which are not comparable.", func() {
+			Ω(func() { NewAssociation(Rule{Key: []int{1}, Value: 1}) }).Should(Panic())
+		})
+	})
+
+	Context("AssociationThread(keys, values)", func() {
+		It("gives the association which maps keys to values.", func() {
+			a := AssociationThread([]string{"x", "y"}, []int{1, 2})
+			Expect(a.Keys()).To(Equal([]interface{}{"x", "y"}))
+			Expect(a.Values()).To(Equal([]interface{}{1, 2}))
+		})
+
+		It("panics when lengths are different.", func() {
+			Ω(func() { AssociationThread([]string{"x"}, []int{1, 2}) }).Should(Panic())
+		})
+	})
+
+	Context("Lookup(assoc, key, default)", func() {
+		It("finds the value of key.", func() {
+			Expect(Lookup(newScores(), "b")).To(Equal(2))
+		})
+
+		It("gives default when key is missing.", func() {
+			Expect(Lookup(newScores(), "z", 0)).To(Equal(0))
+		})
+
+		It("panics when key is missing and there is no default.", func() {
+			Ω(func() { Lookup(newScores(), "z") }).Should(Panic())
+		})
+	})
+
+	Context("KeySort(assoc)", func() {
+		It("orders keys canonically.", func() {
+			a := KeySort(newScores())
+			Expect(a.Keys()).To(Equal([]interface{}{"a", "b", "c"}))
+			Expect(a.Values()).To(Equal([]interface{}{1, 2, 3}))
+		})
+
+		It("orders keys with less.", func() {
+			a := KeySort(newScores(), Greater)
+			Expect(a.Keys()).To(Equal([]interface{}{"c", "b", "a"}))
+		})
+	})
+
+	Context("KeySelect, KeyDrop, KeyTake, KeyMap", func() {
+		It("selects keys for which f is true.", func() {
+			a := KeySelect(newScores(), func(k string) bool { return k != "a" })
+			Expect(a.Keys()).To(Equal([]interface{}{"c", "b"}))
+		})
+
+		It("drops keys.", func() {
+			a := KeyDrop(newScores(), "c", "z")
+			Expect(a.Keys()).To(Equal([]interface{}{"a", "b"}))
+		})
+
+		It("takes keys in the given order.", func() {
+			a := KeyTake(newScores(), "b", "z", "c")
+			Expect(a.Keys()).To(Equal([]interface{}{"b", "c"}))
+			Expect(a.Values()).To(Equal([]interface{}{2, 3}))
+		})
+
+		It("maps f over keys.", func() {
+			a := KeyMap(strings.ToUpper, newScores())
+			Expect(a.Keys()).To(Equal([]interface{}{"C", "A", "B"}))
+			Expect(a.Values()).To(Equal([]interface{}{3, 1, 2}))
+		})
+
+		It("panics when f doesn't accept the keys.", func() {
+			Ω(func() { KeyMap(func(k int) int { return k }, newScores()) }).Should(Panic())
+		})
+	})
+
+	Context("Merge(assocs, combiner)", func() {
+		It("combines values of the same key.", func() {
+			a1 := NewAssociation(Rule{Key: "a", Value: 1}, Rule{Key: "b", Value: 2})
+			a2 := NewAssociation(Rule{Key: "c", Value: 3}, Rule{Key: "a", Value: 4})
+			total := func(xs []interface{}) int {
+				sum := 0
+				for _, x := range xs {
+					sum += x.(int)
+				}
+				return sum
+			}
+			a := Merge([]*Association{a1, a2}, total)
+			Expect(a.Keys()).To(Equal([]interface{}{"a", "b", "c"}))
+			Expect(a.Values()).To(Equal([]interface{}{5, 2, 3}))
+		})
+	})
+
+	Context("list functions", func() {
+		It("Map keeps keys.", func() {
+			a := Map(func(x int) int { return x * 10 }, newScores()).(*Association)
+			Expect(a.Keys()).To(Equal([]interface{}{"c", "a", "b"}))
+			Expect(a.Values()).To(Equal([]interface{}{30, 10, 20}))
+		})
+
+		It("Filter selects values.", func() {
+			a := Filter(func(x int) bool { return x > 1 }, newScores()).(*Association)
+			Expect(a.Keys()).To(Equal([]interface{}{"c", "b"}))
+		})
+
+		It("Fold visits values in order.", func() {
+			concat := func(r string, x int) string { return r + string(rune('0'+x)) }
+			Expect(Fold(concat, "", newScores())).To(Equal("312"))
+		})
+
+		It("Position gives keys.", func() {
+			a := newScores()
+			a.Set("d", 1)
+			Expect(Position(a, 1)).To(Equal([][]interface{}{{"a"}, {"d"}}))
+			Expect(Count(a, 1)).To(Equal(2))
+		})
+
+		It("Keys, Values, KeyMemberQ and Length accept associations.", func() {
+			a := newScores()
+			Expect(Keys(a)).To(Equal([]interface{}{"c", "a", "b"}))
+			Expect(Values(a)).To(Equal([]interface{}{3, 1, 2}))
+			Expect(KeyMemberQ(a, "a")).To(BeTrue())
+			Expect(KeyMemberQ(a, "z")).To(BeFalse())
+			Expect(Length(a)).To(Equal(3))
+		})
+	})
+})
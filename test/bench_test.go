@@ -0,0 +1,126 @@
+package test
+
+import (
+	. "fp"
+	"strconv"
+	"testing"
+)
+
+var benchInts = Range(1000)
+
+func BenchmarkMap(b *testing.B) {
+	square := func(x int) int { return x * x }
+	for i := 0; i < b.N; i++ {
+		Map(square, benchInts)
+	}
+}
+
+func BenchmarkMapLoop(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		ys := make([]int, len(benchInts))
+		for j, x := range benchInts {
+			ys[j] = x * x
+		}
+	}
+}
+
+func BenchmarkFilter(b *testing.B) {
+	evenQ := func(x int) bool { return x%2 == 0 }
+	for i := 0; i < b.N; i++ {
+		Filter(evenQ, benchInts)
+	}
+}
+
+func BenchmarkFold(b *testing.B) {
+	add := func(r, x int) int { return r + x }
+	for i := 0; i < b.N; i++ {
+		Fold(add, 0, benchInts)
+	}
+}
+
+func BenchmarkSort(b *testing.B) {
+	xs := Reverse(benchInts)
+	for i := 0; i < b.N; i++ {
+		Sort(xs)
+	}
+}
+
+func BenchmarkSortWithLess(b *testing.B) {
+	greater := func(a, b interface{}) bool { return a.(int) > b.(int) }
+	for i := 0; i < b.N; i++ {
+		Sort(benchInts, greater)
+	}
+}
+
+func BenchmarkMapSmall(b *testing.B) {
+	square := func(x int) int { return x * x }
+	xs := []int{1, 2, 3}
+	for i := 0; i < b.N; i++ {
+		Map(square, xs)
+	}
+}
+
+// The benchmarks below compare the fast paths of []int, []float64 and
+// []string with the reflective path, which []int32 takes.
+
+var benchFloats = Map(func(x int) float64 { return float64(x) }, benchInts).([]float64)
+
+var benchStrings = Map(strconv.Itoa, benchInts).([]string)
+
+func BenchmarkMapFast(b *testing.B) {
+	half := func(x float64) float64 { return x / 2 }
+	for i := 0; i < b.N; i++ {
+		Map(half, benchFloats)
+	}
+}
+
+func BenchmarkFilterFast(b *testing.B) {
+	shortQ := func(s string) bool { return len(s) < 3 }
+	for i := 0; i < b.N; i++ {
+		Filter(shortQ, benchStrings)
+	}
+}
+
+func BenchmarkFoldFast(b *testing.B) {
+	add := func(r, x float64) float64 { return r + x }
+	for i := 0; i < b.N; i++ {
+		Fold(add, 0.0, benchFloats)
+	}
+}
+
+func BenchmarkSortFast(b *testing.B) {
+	xs := Map(func(x int) int { return -x }, benchInts)
+	for i := 0; i < b.N; i++ {
+		Sort(xs)
+	}
+}
+
+var benchInt32s = Map(func(x int) int32 { return int32(x) }, benchInts).([]int32)
+
+func BenchmarkMapReflect(b *testing.B) {
+	square := func(x int32) int32 { return x * x }
+	for i := 0; i < b.N; i++ {
+		Map(square, benchInt32s)
+	}
+}
+
+func BenchmarkFilterReflect(b *testing.B) {
+	evenQ := func(x int32) bool { return x%2 == 0 }
+	for i := 0; i < b.N; i++ {
+		Filter(evenQ, benchInt32s)
+	}
+}
+
+func BenchmarkFoldReflect(b *testing.B) {
+	add := func(r, x int32) int32 { return r + x }
+	for i := 0; i < b.N; i++ {
+		Fold(add, int32(0), benchInt32s)
+	}
+}
+
+func BenchmarkSortReflect(b *testing.B) {
+	xs := Map(func(x int32) int32 { return -x }, benchInt32s)
+	for i := 0; i < b.N; i++ {
+		Sort(xs)
+	}
+}
@@ -0,0 +1,110 @@
+package test
+
+import (
+	. "fp"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"math"
+	"math/big"
+)
+
+var _ = Describe("big numbers", func() {
+	bigInt := func(s string) *big.Int {
+		z, _ := new(big.Int).SetString(s, 10)
+		return z
+	}
+
+	Context("Pow(x, y)", func() {
+		It("is exact for integers.", func() {
+			Expect(Pow(3, 39)).To(Equal(4052555153018976267))
+			Expect(Pow(int64(-2), 63)).To(Equal(int64(math.MinInt64)))
+			Expect(Pow(uint64(2), 63)).To(Equal(uint64(1) << 63))
+			Expect(Pow(int8(-2), 7)).To(Equal(int8(-128)))
+			Expect(Pow(0, 0)).To(Equal(1))
+		})
+
+		It("promotes to big.Int on overflow.", func() {
+			Expect(Pow(3, 40)).To(Equal(bigInt("12157665459056928801")))
+			Expect(Pow(int64(-2), 65)).To(Equal(bigInt("-36893488147419103232")))
+			Expect(Pow(int8(2), 7)).To(Equal(big.NewInt(128)))
+		})
+
+		It("raises big numbers to integer powers.", func() {
+			Expect(Pow(big.NewInt(10), 20)).To(Equal(bigInt("100000000000000000000")))
+			Expect(Pow(big.NewInt(2), -2)).To(Equal(big.NewRat(1, 4)))
+			Expect(Pow(big.NewRat(-2, 3), 3)).To(Equal(big.NewRat(-8, 27)))
+			Expect(Pow(big.NewRat(2, 3), -2)).To(Equal(big.NewRat(9, 4)))
+			x := new(big.Float).SetPrec(200).SetInt64(3)
+			y := Pow(x, 100).(*big.Float)
+			Expect(y.Prec()).To(Equal(uint(200)))
+			z, _ := y.Int(nil)
+			Expect(z).To(Equal(new(big.Int).Exp(big.NewInt(3), big.NewInt(100), nil)))
+			Ω(func() { Pow(big.NewInt(2), 0.5) }).Should(Panic())
+			Ω(func() { Pow(big.NewRat(0, 1), -1) }).Should(Panic())
+		})
+
+		It("gives big.Rat for negative integer powers of integers.", func() {
+			Expect(Pow(2, -1)).To(Equal(big.NewRat(1, 2)))
+			Expect(Pow(int8(-2), int8(-3))).To(Equal(big.NewRat(-1, 8)))
+			Expect(Pow([]int{2, 4}, -1)).To(Equal([]*big.Rat{big.NewRat(1, 2), big.NewRat(1, 4)}))
+			Ω(func() { Pow(0, -1) }).Should(PanicWith("Pow: 0^-1 is infinite."))
+		})
+
+		It("promotes the base to the type of a big exponent.", func() {
+			Expect(Pow(2, big.NewInt(3))).To(Equal(big.NewInt(8)))
+			Expect(Pow(2, big.NewInt(-1))).To(Equal(big.NewRat(1, 2)))
+			Expect(Pow(2, big.NewRat(3, 1))).To(Equal(big.NewRat(8, 1)))
+			Expect(Pow(big.NewInt(2), big.NewRat(2, 1))).To(Equal(big.NewRat(4, 1)))
+			y := Pow(2, new(big.Float).SetPrec(100).SetInt64(3)).(*big.Float)
+			Expect(y.Prec()).To(Equal(uint(100)))
+			Expect(y.Cmp(big.NewFloat(8))).To(Equal(0))
+			Expect(Pow(2.0, big.NewInt(3))).To(Equal(8.0))
+			Expect(Pow([]int{2, 3}, big.NewInt(2))).To(Equal([]*big.Int{big.NewInt(4), big.NewInt(9)}))
+			Ω(func() { Pow(2, big.NewRat(1, 2)) }).Should(Panic())
+		})
+	})
+
+	Context("Abs, Greater, Max, Min and Sort", func() {
+		It("accepts big numbers.", func() {
+			Expect(Abs(big.NewInt(-5))).To(Equal(big.NewInt(5)))
+			Expect(Abs(big.NewRat(-1, 3))).To(Equal(big.NewRat(1, 3)))
+			Expect(Abs(big.NewFloat(-1.5)).(*big.Float).Cmp(big.NewFloat(1.5))).To(Equal(0))
+			Expect(Greater(bigInt("9007199254740993"), 9007199254740992.0)).To(BeTrue())
+			Expect(Greater(big.NewRat(1, 3), big.NewFloat(0.5))).To(BeFalse())
+			Expect(Greater(new(big.Float).SetInf(false), math.MaxFloat64)).To(BeTrue())
+			Expect(Max(big.NewInt(3), big.NewInt(7), big.NewInt(5))).To(Equal(big.NewInt(7)))
+			Expect(Min([]*big.Rat{big.NewRat(1, 2), big.NewRat(1, 3)})).To(Equal(big.NewRat(1, 3)))
+			Expect(Sort([]*big.Int{big.NewInt(3), big.NewInt(-1), big.NewInt(2)})).To(Equal([]*big.Int{big.NewInt(-1), big.NewInt(2), big.NewInt(3)}))
+		})
+	})
+
+	Context("statistics", func() {
+		It("accepts big numbers.", func() {
+			xs := []*big.Int{bigInt("100000000000000000001"), big.NewInt(1), big.NewInt(2)}
+			Expect(Total(xs)).To(Equal(bigInt("100000000000000000004")))
+			Expect(Total([]*big.Rat{big.NewRat(1, 3), big.NewRat(1, 6)})).To(Equal(big.NewRat(1, 2)))
+			Expect(Total([]*big.Rat{})).To(Equal(new(big.Rat)))
+			Expect(Mean([]*big.Int{big.NewInt(1), big.NewInt(2)})).To(Equal(1.5))
+			Expect(Quantile(xs, 1)).To(Equal(xs[0]))
+		})
+	})
+
+	Context("N(x, digits...)", func() {
+		It("gives float64 without digits.", func() {
+			Expect(N(3)).To(Equal(3.0))
+			Expect(N(big.NewRat(1, 4))).To(Equal(0.25))
+			Expect(N([]int{1, 2})).To(Equal([]float64{1, 2}))
+			Expect(N([][]int{{1}, {2}})).To(Equal([][]float64{{1}, {2}}))
+			Expect(N(complex64(1 + 2i))).To(Equal(1 + 2i))
+		})
+
+		It("gives big.Float with digits.", func() {
+			x := N(big.NewRat(1, 3), 50).(*big.Float)
+			Expect(x.Prec()).To(Equal(uint(167)))
+			Expect(x.Text('g', 50)).To(Equal("0.33333333333333333333333333333333333333333333333333"))
+			Expect(N([]int{1, 2}, 30)).To(HaveLen(2))
+			Ω(func() { N(1i, 10) }).Should(Panic())
+			Ω(func() { N(1, 0) }).Should(Panic())
+		})
+	})
+})
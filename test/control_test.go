@@ -0,0 +1,138 @@
+package test
+
+import (
+	. "fp"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("control", func() {
+	Context("Throw(value, tag) and Catch(f, tags...)", func() {
+		It("gives the result of f without a Throw.", func() {
+			Expect(Catch(func() int { return 1 })).To(Equal(1))
+		})
+
+		It("exits Fold early.", func() {
+			firstOver := func(limit int, xs []int) interface{} {
+				return Catch(func() interface{} {
+					return Fold(func(total, x int) int {
+						if total+x > limit {
+							Throw(x)
+						}
+						return total + x
+					}, 0, xs)
+				})
+			}
+			Expect(firstOver(5, Range(10))).To(Equal(3))
+			Expect(firstOver(100, Range(3))).To(Equal(6))
+		})
+
+		It("catches only the Throws for its tags.", func() {
+			inner := func() interface{} {
+				return Catch(func() { Throw("outer value", "outer") }, "inner")
+			}
+			Expect(Catch(inner, "outer")).To(Equal("outer value"))
+			Ω(func() { Catch(func() { Throw(1, "a") }) }).Should(Panic())
+			Ω(func() { Catch(func() { Throw(1) }, "a") }).Should(Panic())
+		})
+
+		It("lets other panics through.", func() {
+			Ω(func() { Catch(func() { panic("boom") }) }).Should(PanicWith("boom"))
+		})
+
+		It("catches a Throw in ParallelMap workers.", func() {
+			actual := Catch(func() interface{} {
+				return ParallelMap(func(x int) int {
+					if x == 3 {
+						Throw("three")
+					}
+					return x
+				}, Range(5))
+			})
+			Expect(actual).To(Equal("three"))
+		})
+
+		It("catches a Throw in ParallelDo workers.", func() {
+			actual := Catch(func() {
+				ParallelDo(func(x int) { Throw(x, "found") }, []int{7})
+			}, "found")
+			Expect(actual).To(Equal(7))
+		})
+
+		It("panics when f takes arguments.", func() {
+			Ω(func() { Catch(func(x int) {}) }).Should(Panic())
+		})
+	})
+
+	Context("Sow(value, tag) and Reap(f, tags...)", func() {
+		It("collects sown values by tag in order of appearance.", func() {
+			result, sown := Reap(func() int {
+				Do(func(x int) {
+					if x%2 == 0 {
+						Sow(x, "even")
+					} else {
+						Sow(x, "odd")
+					}
+				}, Range(5))
+				return Sow(42).(int)
+			})
+			Expect(result).To(Equal(42))
+			Expect(sown).To(Equal([][]interface{}{{1, 3, 5}, {2, 4}, {42}}))
+		})
+
+		It("gives a list for each tag.", func() {
+			_, sown := Reap(func() {
+				Sow(1, "a")
+				Sow(2, "c")
+			}, "a", "b")
+			Expect(sown).To(Equal([][]interface{}{{1}, {}}))
+		})
+
+		It("gives values to the innermost Reap for their tag.", func() {
+			_, outer := Reap(func() {
+				_, inner := Reap(func() {
+					Sow(1, "inner")
+					Sow(2, "outer")
+				}, "inner")
+				Expect(inner).To(Equal([][]interface{}{{1}}))
+			})
+			Expect(outer).To(Equal([][]interface{}{{2}}))
+		})
+
+		It("collects values sown in ParallelDo workers.", func() {
+			_, sown := Reap(func() {
+				ParallelDo(func(x int) { Sow(x * x) }, Range(100))
+			})
+			Expect(sown).To(HaveLen(1))
+			Expect(Sort(sown[0], func(a, b interface{}) bool { return a.(int) < b.(int) })).To(Equal(Map(func(x int) interface{} { return x * x }, Range(100))))
+		})
+
+		It("keeps Reaps of other goroutines apart.", func() {
+			done := make(chan [][]interface{})
+			go func() {
+				_, sown := Reap(func() { Sow("other") })
+				done <- sown
+			}()
+			_, sown := Reap(func() { Sow("mine") })
+			Expect(sown).To(Equal([][]interface{}{{"mine"}}))
+			Expect(<-done).To(Equal([][]interface{}{{"other"}}))
+		})
+
+		It("doesn't collect values sown on goroutines started in f.", func() {
+			_, sown := Reap(func() {
+				done := make(chan bool)
+				go func() {
+					Sow("lost")
+					close(done)
+				}()
+				<-done
+				Sow("kept")
+			})
+			Expect(sown).To(Equal([][]interface{}{{"kept"}}))
+		})
+
+		It("does nothing without a Reap.", func() {
+			Expect(Sow(1)).To(Equal(1))
+		})
+	})
+})
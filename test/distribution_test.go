@@ -0,0 +1,153 @@
+package test
+
+import (
+	. "fp"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"math"
+	"math/rand"
+)
+
+var _ = Describe("distribution", func() {
+	const tolerance = 1e-9
+
+	Context("PDF, CDF and InverseCDF", func() {
+		It("NormalDistribution(mu, sigma)", func() {
+			d := NormalDistribution(0, 1)
+			Expect(PDF(d, 0)).To(BeNumerically("~", 1/math.Sqrt(2*math.Pi), tolerance))
+			Expect(CDF(d, 0)).To(BeNumerically("~", 0.5, tolerance))
+			Expect(CDF(d, 1.96)).To(BeNumerically("~", 0.9750021048517795, tolerance))
+			Expect(InverseCDF(d, 0.975)).To(BeNumerically("~", 1.959963984540054, tolerance))
+		})
+
+		It("UniformDistribution(min, max)", func() {
+			d := UniformDistribution(0, 2)
+			Expect(PDF(d, []float64{-1, 1, 3})).To(Equal([]float64{0, 0.5, 0}))
+			Expect(CDF(d, 1)).To(Equal(0.5))
+			Expect(InverseCDF(d, 0.25)).To(Equal(0.5))
+		})
+
+		It("ExponentialDistribution(lambda)", func() {
+			d := ExponentialDistribution(2)
+			Expect(CDF(d, 1)).To(BeNumerically("~", 1-math.Exp(-2), tolerance))
+			Expect(InverseCDF(d, CDF(d, 1))).To(BeNumerically("~", 1, tolerance))
+		})
+
+		It("PoissonDistribution(mu)", func() {
+			d := PoissonDistribution(3)
+			Expect(PDF(d, 2)).To(BeNumerically("~", 4.5*math.Exp(-3), tolerance))
+			Expect(PDF(d, 2.5)).To(Equal(0.0))
+			Expect(CDF(d, 2.5)).To(BeNumerically("~", 8.5*math.Exp(-3), tolerance))
+			Expect(InverseCDF(d, []float64{0, 0.5})).To(Equal([]float64{0, 3}))
+		})
+
+		It("BinomialDistribution(n, p)", func() {
+			d := BinomialDistribution(10, 0.5)
+			Expect(PDF(d, 5)).To(BeNumerically("~", 252.0/1024, tolerance))
+			Expect(CDF(d, 5)).To(BeNumerically("~", 638.0/1024, tolerance))
+			Expect(InverseCDF(d, 0.5)).To(Equal(5.0))
+			Expect(InverseCDF(d, 1)).To(Equal(10.0))
+		})
+
+		It("GammaDistribution(alpha, beta)", func() {
+			d := GammaDistribution(2, 3)
+			p := 1 - 2*math.Exp(-1)
+			Expect(CDF(d, 3)).To(BeNumerically("~", p, tolerance))
+			Expect(InverseCDF(d, p)).To(BeNumerically("~", 3, 1e-6))
+		})
+
+		It("BetaDistribution(alpha, beta)", func() {
+			d := BetaDistribution(2, 2)
+			Expect(PDF(d, 0.5)).To(BeNumerically("~", 1.5, tolerance))
+			Expect(CDF(d, 0.3)).To(BeNumerically("~", 0.216, tolerance))
+			Expect(InverseCDF(d, 0.5)).To(BeNumerically("~", 0.5, 1e-9))
+		})
+
+		It("LogNormalDistribution(mu, sigma)", func() {
+			d := LogNormalDistribution(0, 1)
+			Expect(CDF(d, 1)).To(BeNumerically("~", 0.5, tolerance))
+			Expect(PDF(d, -1)).To(Equal(0.0))
+			Expect(InverseCDF(d, 0.5)).To(BeNumerically("~", 1, tolerance))
+		})
+
+		It("EmpiricalDistribution(list)", func() {
+			d := EmpiricalDistribution([]int{3, 2, 1, 2})
+			Expect(PDF(d, 2)).To(Equal(0.5))
+			Expect(CDF(d, 2)).To(Equal(0.75))
+			Expect(InverseCDF(d, 0.5)).To(Equal(2.0))
+		})
+
+		It("panics on invalid parameters and probabilities.", func() {
+			Ω(func() { NormalDistribution(0, 0) }).Should(Panic())
+			Ω(func() { UniformDistribution(1, 1) }).Should(Panic())
+			Ω(func() { BinomialDistribution(3, 1.5) }).Should(Panic())
+			Ω(func() { InverseCDF(NormalDistribution(0, 1), 2) }).Should(Panic())
+		})
+	})
+
+	Context("Mean, Variance, Median and Quantile of distributions", func() {
+		It("gives the statistics of the distribution.", func() {
+			Expect(Mean(GammaDistribution(2, 3))).To(Equal(6.0))
+			Expect(Variance(GammaDistribution(2, 3))).To(Equal(18.0))
+			Expect(StandardDeviation(NormalDistribution(1, 2))).To(Equal(2.0))
+			Expect(Mean(LogNormalDistribution(0, 1))).To(BeNumerically("~", math.Exp(0.5), tolerance))
+			Expect(Variance(EmpiricalDistribution([]int{1, 2, 2, 3}))).To(Equal(0.5))
+			Expect(Median(UniformDistribution(0, 4))).To(Equal(2.0))
+			Expect(Quantile(UniformDistribution(0, 4), []float64{0.25, 0.75})).To(Equal([]float64{1, 3}))
+		})
+	})
+
+	Context("RandomVariate(dist, n, rng...)", func() {
+		It("is reproducible with a seeded source.", func() {
+			d := GammaDistribution(0.5, 1)
+			xs := RandomVariate(d, 5, rand.New(rand.NewSource(7)))
+			ys := RandomVariate(d, 5, rand.New(rand.NewSource(7)))
+			Expect(xs).To(HaveLen(5))
+			Expect(xs).To(Equal(ys))
+		})
+
+		It("samples the distribution.", func() {
+			rng := rand.New(rand.NewSource(1))
+			samples := map[string]Distribution{
+				"normal":   NormalDistribution(5, 2),
+				"poisson":  PoissonDistribution(4),
+				"binomial": BinomialDistribution(20, 0.3),
+				"gamma":    GammaDistribution(2, 3),
+				"beta":     BetaDistribution(2, 5),
+			}
+			for _, d := range samples {
+				xs := RandomVariate(d, 20000, rng)
+				sd := math.Sqrt(d.Variance())
+				Expect(Mean(xs)).To(BeNumerically("~", d.Mean(), 4*sd/math.Sqrt(20000)))
+			}
+			for _, x := range RandomVariate(PoissonDistribution(4), 100, rng) {
+				Expect(x).To(Equal(math.Floor(x)))
+			}
+		})
+
+		It("works with Table.", func() {
+			rng := rand.New(rand.NewSource(3))
+			d := UniformDistribution(0, 1)
+			xs := Table(func() float64 { return RandomVariate(d, 1, rng)[0] }, 4).([]float64)
+			Expect(xs).To(HaveLen(4))
+			Expect(xs).To(Equal(RandomVariate(d, 4, rand.New(rand.NewSource(3)))))
+		})
+	})
+
+	Context("Table(f, iterator...)", func() {
+		It("calls f with the elements of Range.", func() {
+			Expect(Table(func(i int) int { return i * i }, 3)).To(Equal([]int{1, 4, 9}))
+			Expect(Table(func(i int) string { return string(rune('a' + i)) }, 0, 4, 2)).To(Equal([]string{"a", "c", "e"}))
+		})
+
+		It("calls a function without parameters n times.", func() {
+			n := 0
+			Expect(Table(func() int { n++; return n }, 3)).To(Equal([]int{1, 2, 3}))
+		})
+
+		It("panics on a wrong iterator.", func() {
+			Ω(func() { Table(func() int { return 0 }, 1, 2) }).Should(Panic())
+			Ω(func() { Table(func(s string) int { return 0 }, 2) }).Should(Panic())
+		})
+	})
+})
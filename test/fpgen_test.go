@@ -0,0 +1,59 @@
+package test
+
+import (
+	"fp/internal/fpgen"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"os"
+	"path/filepath"
+)
+
+var _ = Describe("fpgen", func() {
+	Context("ParseDirective(line)", func() {
+		It("parses specs separated by spaces.", func() {
+			specs, err := fpgen.ParseDirective(" Map[Order,Invoice]  Sort[int]")
+			Expect(err).To(BeNil())
+			Expect(specs).To(Equal([]fpgen.Spec{
+				{Func: "Map", Types: []string{"Order", "Invoice"}},
+				{Func: "Sort", Types: []string{"int"}},
+			}))
+			Expect(specs[0].Name()).To(Equal("MapOrderInvoice"))
+		})
+
+		It("keeps commas nested in type arguments.", func() {
+			specs, err := fpgen.ParseDirective("Map[map[string]int,[]*pkg.Order]")
+			Expect(err).To(BeNil())
+			Expect(specs[0].Types).To(Equal([]string{"map[string]int", "[]*pkg.Order"}))
+			Expect(specs[0].Name()).To(Equal("MapMapStringIntSlicePtrPkgOrder"))
+		})
+
+		It("gives an error for unknown functions and wrong arities.", func() {
+			_, err := fpgen.ParseDirective("Select[int]")
+			Expect(err).NotTo(BeNil())
+			_, err = fpgen.ParseDirective("Map[int]")
+			Expect(err).NotTo(BeNil())
+			_, err = fpgen.ParseDirective("Map")
+			Expect(err).NotTo(BeNil())
+		})
+	})
+
+	Context("Generate(pkg, fp, specs)", func() {
+		It("gives an error without specs.", func() {
+			_, _, err := fpgen.Generate("p", "fp", nil)
+			Expect(err).NotTo(BeNil())
+		})
+
+		It("is up to date with the generated example.", func() {
+			dir := "generated"
+			pkg, specs, err := fpgen.ScanDir(dir)
+			Expect(err).To(BeNil())
+			code, test, err := fpgen.Generate(pkg, "fp", specs)
+			Expect(err).To(BeNil())
+
+			expectedCode, _ := os.ReadFile(filepath.Join(dir, "fp_gen.go"))
+			expectedTest, _ := os.ReadFile(filepath.Join(dir, "fp_gen_test.go"))
+			Expect(string(code)).To(Equal(string(expectedCode)))
+			Expect(string(test)).To(Equal(string(expectedTest)))
+		})
+	})
+})
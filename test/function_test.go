@@ -1,10 +1,18 @@
 package test
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	. "fp"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"io"
+	"math"
+	"reflect"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
 var _ = Describe("function", func() {
@@ -66,43 +74,129 @@ var _ = Describe("function", func() {
 		})
 	})
 
+	Context("argument checking of Apply and Construct", func() {
+		argumentError := func(f func()) *ArgumentError {
+			var err *ArgumentError
+			func() {
+				defer func() { err, _ = recover().(*ArgumentError) }()
+				f()
+			}()
+			return err
+		}
+
+		It("accepts arguments assignable to interfaces.", func() {
+			read := func(r io.Reader) string {
+				data, _ := io.ReadAll(r)
+				return string(data)
+			}
+			Expect(Construct(read, strings.NewReader("fp"))).To(Equal("fp"))
+			Expect(Apply(strings.Join, []interface{}{nil, ","})).To(Equal(""))
+		})
+
+		It("checks variadic arguments.", func() {
+			err := argumentError(func() { Construct(Range, 1, "2") })
+			Expect(err).NotTo(BeNil())
+			Expect(err.Index).To(Equal(1))
+			Expect(err.Expected).To(Equal(reflect.TypeOf(0)))
+			Expect(err.Actual).To(Equal(reflect.TypeOf("")))
+			Expect(err.Error()).To(Equal("Construct: arguments[1]'s type should be int but not string."))
+		})
+
+		It("checks the number of arguments.", func() {
+			add := func(a, b int) int { return a + b }
+			err := argumentError(func() { Apply(add, []interface{}{1, 2, 3}) })
+			Expect(err).NotTo(BeNil())
+			Expect(err.Index).To(Equal(-1))
+			Expect(err.Error()).To(Equal("Apply: func (int, int) int called with 3 arguments; 2 arguments are expected."))
+			Expect(argumentError(func() { Apply(add, []interface{}{1}) })).NotTo(BeNil())
+		})
+
+		It("rejects nil for non-nillable parameters.", func() {
+			err := argumentError(func() { Construct(strconv.Itoa, nil) })
+			Expect(err.Error()).To(Equal("Construct: arguments[0]'s type should be int but not nil."))
+		})
+
+		It("converts numbers with ApplyConvert and ConstructConvert.", func() {
+			Expect(ApplyConvert(math.Sqrt, []interface{}{16})).To(Equal(4.0))
+			Expect(ConstructConvert(Range, 1.0, int8(3))).To(Equal([]int{1, 2, 3}))
+			Expect(argumentError(func() { Construct(math.Sqrt, 16) })).NotTo(BeNil())
+			Expect(argumentError(func() { ConstructConvert(math.Sqrt, "16") })).NotTo(BeNil())
+		})
+
+		It("refuses conversions which lose the value.", func() {
+			Expect(ConstructConvert(func(x uint8) uint8 { return x }, 255)).To(Equal(uint8(255)))
+			Expect(ConstructConvert(func(x float32) bool { return x != x }, math.NaN())).To(BeTrue())
+			Expect(argumentError(func() { ConstructConvert(func(x int) int { return x }, 1.5) })).NotTo(BeNil())
+			Expect(argumentError(func() { ConstructConvert(func(x uint8) uint8 { return x }, 300) })).NotTo(BeNil())
+			Expect(argumentError(func() { ConstructConvert(func(x uint) uint { return x }, -1) })).NotTo(BeNil())
+			Expect(argumentError(func() { ConstructConvert(func(x int64) int64 { return x }, uint64(math.MaxUint64)) })).NotTo(BeNil())
+			Expect(argumentError(func() { ApplyConvert(func(x float32) float32 { return x }, []interface{}{0.1}) })).NotTo(BeNil())
+		})
+	})
+
 	Context("Composition(f, g, h...)", func() {
 		It("0 function", func() {
-			f := Composition()
+			f := Composition().(func(...interface{}) interface{})
 			actual := f(4)
 			expected := 4
 			Expect(actual).To(Equal(expected))
+			Ω(func() { f(1, 2) }).Should(PanicWith("Identity: Identity called with 2 arguments; 1 argument is expected."))
 		})
 
 		It("1 function", func() {
 			s := 1
 			g := func(x int) { s = x}
-			f := Composition(g)
+			f := Composition(g).(func(int))
 			f(4)
 			expected := 4
 			Expect(s).To(Equal(expected))
 		})
 
 		It("1 function", func() {
-			f := Composition(Range)
+			f := Composition(Range).(func(...int) []int)
 			actual := f(4)
 			expected := []int{1,2,3,4}
 			Expect(actual).To(Equal(expected))
 		})
 
 		It("2 functions", func() {
-			f := Composition(Reverse, Range)
+			f := Composition(Reverse, Range).(func(...int) []interface{})
 			actual := f(4)
 			expected := []interface{}{4,3,2,1}
 			Expect(actual).To(Equal(expected))
 		})
 
 		It("3 functions", func() {
-			f := Composition(Reverse, Reverse, Range)
+			f := Composition(Reverse, Reverse, Range).(func(...int) []interface{})
 			actual := f(4)
 			expected := []interface{}{1,2,3,4}
 			Expect(actual).To(Equal(expected))
 		})
+
+		It("passes multiple results and slices to variadic functions", func() {
+			divMod := func(a, b int) (int, int) { return a / b, a % b }
+			sum := func(xs ...int) int { return Fold(func(r, x int) int { return r + x }, 0, xs).(int) }
+			Expect(Composition(sum, divMod).(func(int, int) int)(7, 2)).To(Equal(4))
+			Expect(Composition(sum, Range).(func(...int) int)(4)).To(Equal(10))
+		})
+
+		It("can be passed to Map", func() {
+			f := Composition(strconv.Itoa, func(x int) int { return x * x })
+			Expect(Map(f, []int{1, 2, 3})).To(Equal([]string{"1", "4", "9"}))
+		})
+
+		It("panics when adjacent functions don't match", func() {
+			Ω(func() { Composition(strings.ToUpper, Range) }).Should(Panic())
+			Ω(func() { Composition(strconv.Itoa, strconv.Atoi) }).Should(Panic())
+			Ω(func() { Composition(1, Range) }).Should(Panic())
+		})
+	})
+
+	Context("RightComposition(f, g, h...)", func() {
+		It("applies functions in the given order", func() {
+			f := RightComposition(Range, Reverse).(func(...int) []interface{})
+			Expect(f(3)).To(Equal([]interface{}{3, 2, 1}))
+		})
 	})
 
 	Context("Bind(f, g, h...)", func() {
@@ -196,4 +290,229 @@ var _ = Describe("function", func() {
 			Expect(actual).To(Equal(expected))
 		})
 	})
-})
\ No newline at end of file
+
+	Context("NewBinder(f, g, h...)", func() {
+		parse := func(s string) (int, error) { return strconv.Atoi(s) }
+		divMod := func(a int) (int, int, error) {
+			if a == 0 {
+				return 0, 0, errors.New("zero")
+			}
+			return a / 3, a % 3, nil
+		}
+
+		It("gives all results of the last function.", func() {
+			actual, err := BindAll(parse, divMod)("10")
+			Expect(err).To(BeNil())
+			Expect(actual).To(Equal([]interface{}{3, 1}))
+		})
+
+		It("wraps the error with the failing step.", func() {
+			_, err := BindAll(parse, divMod)("0")
+			var bindErr *BindError
+			Expect(errors.As(err, &bindErr)).To(BeTrue())
+			Expect(bindErr.Index).To(Equal(1))
+			Expect(bindErr.Signature).To(Equal("func (int) (int, int, error)"))
+			Expect(bindErr.Err).To(MatchError("zero"))
+			Expect(err).To(MatchError("Bind: step 1 func (int) (int, int, error) failed: zero"))
+		})
+
+		It("passes ctx to steps which take it.", func() {
+			type key struct{}
+			user := func(ctx context.Context, id int) (string, error) {
+				return fmt.Sprintf("%v#%v", ctx.Value(key{}), id), nil
+			}
+			ctx := context.WithValue(context.Background(), key{}, "admin")
+			actual, err := BindContext(parse, user)(ctx, "7")
+			Expect(err).To(BeNil())
+			Expect(actual).To(Equal("admin#7"))
+		})
+
+		It("stops when ctx is cancelled.", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			stop := func(x int) (int, error) {
+				cancel()
+				return x, nil
+			}
+			_, err := NewBinder(parse, stop, divMod).Run(ctx, "1")
+			Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+			Expect(err.(*BindError).Index).To(Equal(2))
+		})
+
+		It("turns panics into errors with OnPanic.", func() {
+			explode := func(x int) (int, error) { panic("boom") }
+			b := NewBinder(parse, explode).OnPanic(func(step int, r interface{}) error {
+				return fmt.Errorf("recovered %v at %v", r, step)
+			})
+			_, err := b.Run(context.Background(), "1")
+			Expect(errors.Unwrap(err)).To(MatchError("recovered boom at 1"))
+			Ω(func() { NewBinder(parse, explode).Run(context.Background(), "1") }).Should(Panic())
+		})
+
+		It("accepts other error interfaces as the last result.", func() {
+			type codedError interface {
+				error
+				Code() int
+			}
+			check := func(x int) (int, codedError) { return x, nil }
+			actual, err := BindAll(parse, check)("3")
+			Expect(err).To(BeNil())
+			Expect(actual).To(Equal([]interface{}{3}))
+		})
+
+		It("panics when adjacent functions don't match.", func() {
+			Ω(func() { NewBinder(parse, parse) }).Should(Panic())
+			Ω(func() { NewBinder(strconv.Itoa) }).Should(Panic())
+			Ω(func() { Bind(parse, divMod) }).Should(Panic())
+		})
+	})
+	Context("Through(fs, args...)", func() {
+		It("applies each function to the arguments.", func() {
+			divMod := func(a, b int) (int, int) { return a / b, a % b }
+			add := func(a, b int) int { return a + b }
+			actual := Through([]interface{}{add, divMod}, 7, 2)
+			Expect(actual).To(Equal([]interface{}{9, []interface{}{3, 1}}))
+		})
+
+		It("panics when a function doesn't accept the arguments.", func() {
+			Ω(func() { Through([]interface{}{strconv.Itoa}, "a") }).Should(Panic())
+		})
+	})
+
+	Context("Comap(fs, x)", func() {
+		It("gives a typed slice.", func() {
+			fs := []func(string) string{strings.ToUpper, strings.ToLower, strings.TrimSpace}
+			Expect(Comap(fs, " Go ")).To(Equal([]string{" GO ", " go ", "Go"}))
+		})
+
+		It("panics when result types differ.", func() {
+			Ω(func() { Comap([]interface{}{strings.ToUpper, utf8.RuneCountInString}, "a") }).Should(Panic())
+		})
+	})
+
+	Context("Thread(f, args...)", func() {
+		pow := func(x, n int) int { return Pow(x, n).(int) }
+
+		It("threads f over lists.", func() {
+			Expect(Thread(pow, []int{1, 2, 3}, []int{3, 2, 1})).To(Equal([]int{1, 4, 3}))
+		})
+
+		It("passes other arguments to every call.", func() {
+			Expect(Thread(pow, []int{1, 2, 3}, 2)).To(Equal([]int{1, 4, 9}))
+			join := func(xs []string, sep string) string { return strings.Join(xs, sep) }
+			Expect(Thread(join, []string{"a", "b"}, []string{"-", "+"})).To(Equal([]string{"a-b", "a+b"}))
+		})
+
+		It("panics when lists have different lengths.", func() {
+			Ω(func() { Thread(pow, []int{1, 2}, []int{1}) }).Should(Panic())
+		})
+	})
+
+	Context("ApplyAt(f, expr, level)", func() {
+		add := func(a, b int) int { return a + b }
+
+		It("applies f to the elements of each part at level 1.", func() {
+			Expect(ApplyAt(add, [][]int{{1, 2}, {3, 4}})).To(Equal([]int{3, 7}))
+		})
+
+		It("applies f at deeper levels.", func() {
+			Expect(ApplyAt(add, [][][]int{{{1, 2}}, {{3, 4}, {5, 6}}}, 2)).To(Equal([][]int{{3}, {7, 11}}))
+		})
+
+		It("gives multiple results as slices.", func() {
+			divMod := func(a, b int) (int, int) { return a / b, a % b }
+			Expect(ApplyAt(divMod, [][]int{{7, 2}})).To(Equal([]interface{}{[]int{3, 1}}))
+		})
+
+		It("gives multiple results of different types as []interface{}.", func() {
+			describe := func(a, b int) (int, string) { return a + b, strconv.Itoa(a) }
+			Expect(ApplyAt(describe, [][]int{{1, 2}})).To(Equal([]interface{}{[]interface{}{3, "1"}}))
+		})
+
+		It("reports argument errors as ApplyAt.", func() {
+			Ω(func() { ApplyAt(add, [][]int{{1, 2, 3}}) }).Should(PanicWith(MatchError(ContainSubstring("ApplyAt"))))
+			Ω(func() { ApplyAt(add, [][]string{{"a", "b"}}) }).Should(PanicWith(MatchError(ContainSubstring("ApplyAt"))))
+		})
+	})
+
+	Context("Curry(f)", func() {
+		It("takes arguments one at a time.", func() {
+			volume := func(a, b, c int) int { return a * b * c }
+			curried := Curry(volume).(func(int) func(int) func(int) int)
+			Expect(curried(2)(3)(4)).To(Equal(24))
+		})
+
+		It("can be used with Map.", func() {
+			add := func(a, b int) int { return a + b }
+			adders := Map(Curry(add), Range(3)).([]func(int) int)
+			Expect(adders[2](10)).To(Equal(13))
+		})
+
+		It("keeps every result.", func() {
+			divmod := func(a, b int) (int, int) { return a / b, a % b }
+			q, r := Curry(divmod).(func(int) func(int) (int, int))(7)(2)
+			Expect([]int{q, r}).To(Equal([]int{3, 1}))
+		})
+
+		It("panics when f is variadic.", func() {
+			Ω(func() { Curry(Range) }).Should(Panic())
+		})
+	})
+
+	Context("Partial(f, args...)", func() {
+		sub := func(a, b int) int { return a - b }
+
+		It("fixes the leading arguments.", func() {
+			actual := Map(Partial(sub, 10), Range(3))
+			expected := []int{9, 8, 7}
+			Expect(actual).To(Equal(expected))
+		})
+
+		It("leaves Slot arguments open.", func() {
+			actual := Map(Partial(sub, Slot, 10), Range(3))
+			expected := []int{-9, -8, -7}
+			Expect(actual).To(Equal(expected))
+		})
+
+		It("can be used with Filter.", func() {
+			greaterThan := func(x int, limit int) bool { return x > limit }
+			actual := Filter(Partial(greaterThan, Slot, 3), Range(5))
+			Expect(actual).To(Equal([]int{4, 5}))
+		})
+
+		It("keeps the variadic parameter open.", func() {
+			join := func(sep string, words ...string) string { return strings.Join(words, sep) }
+			f := Partial(join, "-", "a").(func(...string) string)
+			Expect(f("b", "c")).To(Equal("a-b-c"))
+			Expect(f()).To(Equal("a"))
+		})
+
+		It("panics when an argument has the wrong type.", func() {
+			Ω(func() { Partial(sub, "10") }).Should(Panic())
+		})
+
+		It("panics when there are too many arguments.", func() {
+			Ω(func() { Partial(sub, 1, 2, 3) }).Should(Panic())
+		})
+	})
+
+	Context("OperatorApplied(f)", func() {
+		It("takes the first argument last.", func() {
+			repeat := func(s string, n int) string { return strings.Repeat(s, n) }
+			twice := OperatorApplied(repeat).(func(int) func(string) string)(2)
+			actual := Map(twice, []string{"a", "bc"})
+			Expect(actual).To(Equal([]string{"aa", "bcbc"}))
+		})
+	})
+
+	Context("ReverseApplied(f)", func() {
+		It("takes the arguments in reverse order.", func() {
+			sub := func(a, b int) int { return a - b }
+			actual := ReverseApplied(sub).(func(int, int) int)(1, 10)
+			Expect(actual).To(Equal(9))
+		})
+
+		It("panics when f is variadic.", func() {
+			Ω(func() { ReverseApplied(Range) }).Should(Panic())
+		})
+	})
+})
@@ -0,0 +1,182 @@
+// Code generated by fpgen. DO NOT EDIT.
+
+package generated
+
+import (
+	"fp"
+)
+
+// MapOrderInvoice is Map specialized for Order and Invoice.
+func MapOrderInvoice(f func(Order) Invoice, xs []Order) []Invoice {
+	ys := make([]Invoice, len(xs))
+	for i, x := range xs {
+		ys[i] = f(x)
+	}
+	return ys
+}
+
+// FilterOrder is Filter specialized for Order.
+func FilterOrder(f func(Order) bool, xs []Order) []Order {
+	ys := []Order{}
+	for _, x := range xs {
+		if f(x) {
+			ys = append(ys, x)
+		}
+	}
+	return ys
+}
+
+// FoldOrderFloat64 is Fold specialized for Order and float64.
+func FoldOrderFloat64(f func(float64, Order) float64, initial float64, xs []Order) float64 {
+	result := initial
+	for _, x := range xs {
+		result = f(result, x)
+	}
+	return result
+}
+
+type sortableOrder []Order
+
+func (xs sortableOrder) Len() int      { return len(xs) }
+func (xs sortableOrder) Swap(i, j int) { xs[i], xs[j] = xs[j], xs[i] }
+
+// SortOrder is Sort specialized for Order, it gives a sorted copy of xs.
+func SortOrder(xs []Order, less func(a, b Order) bool) []Order {
+	ys := make([]Order, len(xs))
+	copy(ys, xs)
+	if less == nil {
+		panic("SortOrder: less is nil; Order has no canonical order.")
+	}
+	fp.SortWith(sortableOrder(ys), func(i, j int) bool { return less(ys[i], ys[j]) })
+	return ys
+}
+
+// GroupByOrderString is GroupBy specialized for Order and string, the values of the
+// association are []Order.
+func GroupByOrderString(f func(Order) string, xs []Order) *fp.Association {
+	groups := fp.NewAssociation()
+	for _, x := range xs {
+		key := f(x)
+		group, ok := groups.Get(key)
+		if !ok {
+			group = make([]Order, 0, 1)
+		}
+		groups.Set(key, append(group.([]Order), x))
+	}
+	return groups
+}
+
+// MapIntString is Map specialized for int and string.
+func MapIntString(f func(int) string, xs []int) []string {
+	ys := make([]string, len(xs))
+	for i, x := range xs {
+		ys[i] = f(x)
+	}
+	return ys
+}
+
+type sortableInt []int
+
+func (xs sortableInt) Len() int      { return len(xs) }
+func (xs sortableInt) Swap(i, j int) { xs[i], xs[j] = xs[j], xs[i] }
+
+// SortInt is Sort specialized for int, it gives a sorted copy of xs.
+// A nil less sorts in canonical order.
+func SortInt(xs []int, less func(a, b int) bool) []int {
+	ys := make([]int, len(xs))
+	copy(ys, xs)
+	if less == nil {
+		less = func(a, b int) bool { return !(a > b) }
+	}
+	fp.SortWith(sortableInt(ys), func(i, j int) bool { return less(ys[i], ys[j]) })
+	return ys
+}
+
+type sortableString []string
+
+func (xs sortableString) Len() int      { return len(xs) }
+func (xs sortableString) Swap(i, j int) { xs[i], xs[j] = xs[j], xs[i] }
+
+// SortString is Sort specialized for string, it gives a sorted copy of xs.
+// A nil less sorts in canonical order.
+func SortString(xs []string, less func(a, b string) bool) []string {
+	ys := make([]string, len(xs))
+	copy(ys, xs)
+	if less == nil {
+		less = func(a, b string) bool { return !(a > b) }
+	}
+	fp.SortWith(sortableString(ys), func(i, j int) bool { return less(ys[i], ys[j]) })
+	return ys
+}
+
+// UnionInt is Union specialized for int.
+func UnionInt(lists ...[]int) []int {
+	seen := map[int]bool{}
+	ys := []int{}
+	for _, xs := range lists {
+		for _, x := range xs {
+			if !seen[x] {
+				seen[x] = true
+				ys = append(ys, x)
+			}
+		}
+	}
+	return ys
+}
+
+// IntersectionInt is Intersection specialized for int, the result follows the
+// order of the last list.
+func IntersectionInt(lists ...[]int) []int {
+	ys := []int{}
+	if len(lists) == 0 {
+		return ys
+	}
+	common := map[int]bool{}
+	for _, x := range lists[0] {
+		if !common[x] {
+			common[x] = true
+			ys = append(ys, x)
+		}
+	}
+	for _, xs := range lists[1:] {
+		next := map[int]bool{}
+		ys = []int{}
+		for _, x := range xs {
+			if common[x] && !next[x] {
+				next[x] = true
+				ys = append(ys, x)
+			}
+		}
+		common = next
+	}
+	return ys
+}
+
+// ComplementInt is Complement specialized for int.
+func ComplementInt(xs []int, ys []int) []int {
+	seen := map[int]bool{}
+	for _, y := range ys {
+		seen[y] = true
+	}
+	zs := []int{}
+	for _, x := range xs {
+		if !seen[x] {
+			seen[x] = true
+			zs = append(zs, x)
+		}
+	}
+	return zs
+}
+
+// DeleteDuplicatesOrder is DeleteDuplicates specialized for Order.
+func DeleteDuplicatesOrder(xs []Order) []Order {
+	seen := map[Order]bool{}
+	ys := []Order{}
+	for _, x := range xs {
+		if !seen[x] {
+			seen[x] = true
+			ys = append(ys, x)
+		}
+	}
+	return ys
+}
@@ -0,0 +1,136 @@
+// Code generated by fpgen. DO NOT EDIT.
+
+package generated
+
+import (
+	"fmt"
+	"fp"
+	"hash/fnv"
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+)
+
+// fpgenHash gives a hash of the values of args.
+func fpgenHash(args ...interface{}) uint64 {
+	h := fnv.New64a()
+	fmt.Fprint(h, args...)
+	return h.Sum64()
+}
+
+// fpgenValue gives a random value of type t which only depends on args,
+// so the functions under test are pure.
+func fpgenValue(t reflect.Type, args ...interface{}) reflect.Value {
+	v, ok := quick.Value(t, rand.New(rand.NewSource(int64(fpgenHash(args...)))))
+	if !ok {
+		panic(fmt.Sprintf("fpgen: testing/quick can't make a value of %v.", t))
+	}
+	return v
+}
+
+func fpgenCheck(t *testing.T, f interface{}) {
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMapOrderInvoice(t *testing.T) {
+	f := func(x Order) Invoice {
+		return fpgenValue(reflect.TypeOf((*Invoice)(nil)).Elem(), x).Interface().(Invoice)
+	}
+	fpgenCheck(t, func(xs []Order) bool {
+		return reflect.DeepEqual(MapOrderInvoice(f, xs), fp.Map(f, xs))
+	})
+}
+
+func TestFilterOrder(t *testing.T) {
+	f := func(x Order) bool { return fpgenHash(x)%2 == 0 }
+	fpgenCheck(t, func(xs []Order) bool {
+		return reflect.DeepEqual(FilterOrder(f, xs), fp.Filter(f, xs))
+	})
+}
+
+func TestFoldOrderFloat64(t *testing.T) {
+	f := func(r float64, x Order) float64 {
+		return fpgenValue(reflect.TypeOf((*float64)(nil)).Elem(), r, x).Interface().(float64)
+	}
+	fpgenCheck(t, func(initial float64, xs []Order) bool {
+		return reflect.DeepEqual(FoldOrderFloat64(f, initial, xs), fp.Fold(f, initial, xs))
+	})
+}
+
+func TestSortOrder(t *testing.T) {
+	less := func(a, b Order) bool { return fpgenHash(a) < fpgenHash(b) }
+	fpgenCheck(t, func(xs []Order) bool {
+		expected := fp.Sort(xs, func(a, b interface{}) bool { return less(a.(Order), b.(Order)) })
+		return reflect.DeepEqual(SortOrder(xs, less), expected)
+	})
+}
+
+func TestGroupByOrderString(t *testing.T) {
+	f := func(x Order) string {
+		return fpgenValue(reflect.TypeOf((*string)(nil)).Elem(), fpgenHash(x)%3).Interface().(string)
+	}
+	fpgenCheck(t, func(xs []Order) bool {
+		return reflect.DeepEqual(GroupByOrderString(f, xs), fp.GroupBy(f, xs))
+	})
+}
+
+func TestMapIntString(t *testing.T) {
+	f := func(x int) string {
+		return fpgenValue(reflect.TypeOf((*string)(nil)).Elem(), x).Interface().(string)
+	}
+	fpgenCheck(t, func(xs []int) bool {
+		return reflect.DeepEqual(MapIntString(f, xs), fp.Map(f, xs))
+	})
+}
+
+func TestSortInt(t *testing.T) {
+	less := func(a, b int) bool { return fpgenHash(a) < fpgenHash(b) }
+	fpgenCheck(t, func(xs []int) bool {
+		expected := fp.Sort(xs, func(a, b interface{}) bool { return less(a.(int), b.(int)) })
+		return reflect.DeepEqual(SortInt(xs, less), expected)
+	})
+	fpgenCheck(t, func(xs []int) bool {
+		return reflect.DeepEqual(SortInt(xs, nil), fp.Sort(xs))
+	})
+}
+
+func TestSortString(t *testing.T) {
+	less := func(a, b string) bool { return fpgenHash(a) < fpgenHash(b) }
+	fpgenCheck(t, func(xs []string) bool {
+		expected := fp.Sort(xs, func(a, b interface{}) bool { return less(a.(string), b.(string)) })
+		return reflect.DeepEqual(SortString(xs, less), expected)
+	})
+	fpgenCheck(t, func(xs []string) bool {
+		return reflect.DeepEqual(SortString(xs, nil), fp.Sort(xs))
+	})
+}
+
+func TestUnionInt(t *testing.T) {
+	fpgenCheck(t, func(xs, ys []int) bool {
+		return reflect.DeepEqual(UnionInt(xs, ys, xs), fp.Union(xs, ys, xs))
+	})
+}
+
+func TestIntersectionInt(t *testing.T) {
+	fpgenCheck(t, func(xs, ys []int) bool {
+		zs := append(append(append([]int{}, ys...), xs...), xs...)
+		return reflect.DeepEqual(IntersectionInt(zs, xs, zs), fp.Intersection(zs, xs, zs))
+	})
+}
+
+func TestComplementInt(t *testing.T) {
+	fpgenCheck(t, func(xs, ys []int) bool {
+		zs := append(append(append([]int{}, xs...), ys...), xs...)
+		return reflect.DeepEqual(ComplementInt(zs, ys), fp.Complement(zs, ys))
+	})
+}
+
+func TestDeleteDuplicatesOrder(t *testing.T) {
+	fpgenCheck(t, func(xs, ys []Order) bool {
+		zs := append(append(append([]Order{}, xs...), ys...), xs...)
+		return reflect.DeepEqual(DeleteDuplicatesOrder(zs), fp.DeleteDuplicates(zs))
+	})
+}
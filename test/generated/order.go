@@ -0,0 +1,20 @@
+// Package generated is an example of fpgen, fp_gen.go and fp_gen_test.go
+// are made by go generate.
+package generated
+
+//go:generate go run fp/cmd/fpgen
+//fp:generate Map[Order,Invoice] Filter[Order] Fold[Order,float64] Sort[Order]
+//fp:generate GroupBy[Order,string] Map[int,string] Sort[int] Sort[string]
+//fp:generate Union[int] Intersection[int] Complement[int] DeleteDuplicates[Order]
+
+type Order struct {
+	ID       int
+	Customer string
+	Amount   float64
+}
+
+type Invoice struct {
+	OrderID int
+	Total   float64
+	Paid    bool
+}
@@ -137,6 +137,17 @@ var _ = Describe("list", func() {
 	})
 
 	Context("ParallelMap(f, expr)", func() {
+		It("raises a panic of a worker in the caller.", func() {
+			f := func(x int) int {
+				if x == 3 {
+					panic("three")
+				}
+				return x
+			}
+			Ω(func() { ParallelMap(f, Range(5)) }).Should(PanicWith("three"))
+			Ω(func() { ParallelDo(func(x int) { f(x) }, Range(5)) }).Should(PanicWith("three"))
+		})
+
 		It("applies f to each element in expr.", func() {
 			add1 := func(x int) int {
 				time.Sleep(10 * time.Millisecond)
@@ -800,6 +811,15 @@ var _ = Describe("list", func() {
 			Expect(actual).To(Equal(expected))
 		})
 
+		It("intersects every list, not only the first and the last.", func() {
+			Expect(Intersection([]int{1, 2}, []int{5}, []int{1, 2})).To(Equal([]int{}))
+			Expect(Intersection([]int{1, 2, 3}, []int{3, 2}, []int{2, 3, 4})).To(Equal([]int{2, 3}))
+		})
+
+		It("gives the elements of a single list once.", func() {
+			Expect(Intersection([]int{2, 1, 2})).To(Equal([]int{2, 1}))
+		})
+
 		It("[]Person, []Person", func() {
 			type Person struct {
 				name string
@@ -858,6 +878,16 @@ var _ = Describe("list", func() {
 			expected := []int{3}
 			Expect(actual).To(Equal(expected))
 		})
+
+		It("keeps the order of list1 without duplicates.", func() {
+			xs := []int{5, 1, 4, 1, 2, 5, 3}
+			ys := []int{2}
+			Expect(Complement(xs, ys)).To(Equal([]int{5, 1, 4, 3}))
+		})
+
+		It("panics when the element types differ.", func() {
+			Ω(func() { Complement([]int{1}, []string{"a"}) }).Should(PanicWith("Complement: [1]'s type should as same as [a]'s type."))
+		})
 	})
 
 	Context("Transpose(lists)", func() {
@@ -879,4 +909,231 @@ var _ = Describe("list", func() {
 			Expect(fmt.Sprintf("%v", actual)).To(Equal(fmt.Sprintf("%v", expected)))
 		})
 	})
-})
\ No newline at end of file
+	Context("Map, Filter, Fold, Do and MapIndexed over maps", func() {
+		m := map[string]int{"b": 2, "a": 1, "c": 3}
+
+		It("Map keeps keys.", func() {
+			actual := Map(func(x int) string { return strconv.Itoa(x * 10) }, m)
+			expected := map[string]string{"a": "10", "b": "20", "c": "30"}
+			Expect(actual).To(Equal(expected))
+		})
+
+		It("Filter selects values.", func() {
+			actual := Filter(func(x int) bool { return x%2 == 1 }, m)
+			expected := map[string]int{"a": 1, "c": 3}
+			Expect(actual).To(Equal(expected))
+		})
+
+		It("Select is Filter.", func() {
+			actual := Select(func(x int) bool { return x > 2 }, m)
+			expected := map[string]int{"c": 3}
+			Expect(actual).To(Equal(expected))
+		})
+
+		It("Fold visits values in key order.", func() {
+			concat := func(r string, x int) string { return r + strconv.Itoa(x) }
+			Expect(Fold(concat, "", m)).To(Equal("123"))
+		})
+
+		It("Do visits values in key order.", func() {
+			actual := []int{}
+			Do(func(x int) { actual = append(actual, x) }, m)
+			Expect(actual).To(Equal([]int{1, 2, 3}))
+		})
+
+		It("visits values of keys without canonical order in any order.", func() {
+			type point struct{ x, y int }
+			m := map[point]int{{1, 2}: 1, {0, 0}: 2, {2, 1}: 3}
+			actual := []int{}
+			Do(func(x int) { actual = append(actual, x) }, m)
+			Expect(actual).To(ConsistOf(1, 2, 3))
+			Expect(Fold(func(r, x int) int { return r + x }, 0, m)).To(Equal(6))
+		})
+
+		It("MapIndexed gives the key as second argument.", func() {
+			actual := MapIndexed(func(x int, k string) string { return k + strconv.Itoa(x) }, m)
+			expected := map[string]string{"a": "a1", "b": "b2", "c": "c3"}
+			Expect(actual).To(Equal(expected))
+		})
+
+		It("panics when f doesn't accept the values.", func() {
+			Ω(func() { Map(func(x string) string { return x }, m) }).Should(Panic())
+		})
+	})
+
+	Context("KeyValueMap(f, dict)", func() {
+		It("applies f to each key and value.", func() {
+			m := map[string]int{"b": 2, "a": 1}
+			actual := KeyValueMap(func(k string, v int) string { return k + "=" + strconv.Itoa(v) }, m)
+			expected := []string{"a=1", "b=2"}
+			Expect(actual).To(Equal(expected))
+		})
+
+		It("association", func() {
+			a := NewAssociation(Rule{Key: "b", Value: 2}, Rule{Key: "a", Value: 1})
+			actual := KeyValueMap(func(k string, v int) string { return k + "=" + strconv.Itoa(v) }, a)
+			expected := []string{"b=2", "a=1"}
+			Expect(actual).To(Equal(expected))
+		})
+	})
+
+	Context("MapAt(f, dict, keys...)", func() {
+		It("applies f to the values at keys.", func() {
+			m := map[string]int{"a": 1, "b": 2, "c": 3}
+			actual := MapAt(func(x int) int { return -x }, m, "a", "c")
+			expected := map[string]int{"a": -1, "b": 2, "c": -3}
+			Expect(actual).To(Equal(expected))
+			Expect(m["a"]).To(Equal(1))
+		})
+
+		It("association", func() {
+			a := NewAssociation(Rule{Key: "a", Value: 1}, Rule{Key: "b", Value: 2})
+			actual := MapAt(func(x int) int { return -x }, a, "b").(*Association)
+			Expect(actual.Values()).To(Equal([]interface{}{1, -2}))
+		})
+
+		It("panics when a key is missing.", func() {
+			m := map[string]int{"a": 1}
+			Ω(func() { MapAt(func(x int) int { return -x }, m, "z") }).Should(Panic())
+		})
+	})
+
+	Context("MapAt(f, list, positions...)", func() {
+		negate := func(x int) int { return -x }
+
+		It("applies f at indices, counting negative ones from the end.", func() {
+			xs := []int{1, 2, 3, 4}
+			Expect(MapAt(negate, xs, 0, -1)).To(Equal([]int{-1, 2, 3, -4}))
+			Expect(xs).To(Equal([]int{1, 2, 3, 4}))
+		})
+
+		It("applies f at paths into nested lists.", func() {
+			xss := [][]int{{1, 2}, {3, 4}}
+			Expect(MapAt(negate, xss, []int{1, 0})).To(Equal([][]int{{1, 2}, {-3, 4}}))
+			Expect(xss[1][0]).To(Equal(3))
+		})
+
+		It("panics when a position is out of range.", func() {
+			Ω(func() { MapAt(negate, []int{1}, 1) }).Should(Panic())
+			Ω(func() { MapAt(negate, []int{1}, []int{0, 0}) }).Should(Panic())
+		})
+	})
+
+	Context("Map, Filter, Fold, Do and MapIndexed over structs", func() {
+		type Scores struct {
+			Math    int
+			Physics int
+			Art     int
+			comment int
+		}
+		s := Scores{Math: 90, Physics: 70, Art: 80, comment: 1}
+
+		It("Map gives a map of exported field names.", func() {
+			actual := Map(func(x int) bool { return x >= 80 }, s)
+			expected := map[string]bool{"Math": true, "Physics": false, "Art": true}
+			Expect(actual).To(Equal(expected))
+		})
+
+		It("Filter selects fields.", func() {
+			actual := Filter(func(x int) bool { return x >= 80 }, s)
+			expected := map[string]int{"Math": 90, "Art": 80}
+			Expect(actual).To(Equal(expected))
+		})
+
+		It("Fold visits fields in declaration order.", func() {
+			concat := func(r string, x int) string { return r + strconv.Itoa(x) }
+			Expect(Fold(concat, "", s)).To(Equal("907080"))
+		})
+
+		It("Do visits fields in declaration order.", func() {
+			actual := []interface{}{}
+			Do(func(x interface{}) { actual = append(actual, x) }, s)
+			Expect(actual).To(Equal([]interface{}{90, 70, 80}))
+		})
+
+		It("MapIndexed gives the field name as second argument.", func() {
+			actual := MapIndexed(func(x int, name string) string { return name + strconv.Itoa(x) }, s)
+			expected := map[string]string{"Math": "Math90", "Physics": "Physics70", "Art": "Art80"}
+			Expect(actual).To(Equal(expected))
+		})
+
+		It("panics when a field's type doesn't match.", func() {
+			type Person struct {
+				Name string
+				Age  int
+			}
+			Ω(func() { Map(func(x int) int { return x }, Person{Name: "a", Age: 1}) }).Should(Panic())
+		})
+	})
+	Context("GroupBy(f, list)", func() {
+		It("gathers elements into groups by f.", func() {
+			groups := GroupBy(func(x int) bool { return x%2 == 0 }, Range(5))
+			Expect(groups.Keys()).To(Equal([]interface{}{false, true}))
+			Expect(groups.Values()).To(Equal([]interface{}{[]int{1, 3, 5}, []int{2, 4}}))
+		})
+	})
+
+	Context("Map(f, list, levelspec)", func() {
+		xss := [][]int{{1, 2}, {3}}
+
+		It("maps at level n only.", func() {
+			actual := Map(strconv.Itoa, xss, []int{2})
+			Expect(actual).To(Equal([][]string{{"1", "2"}, {"3"}}))
+		})
+
+		It("maps at levels 1 through n.", func() {
+			wrap := func(x interface{}) interface{} { return fmt.Sprintf("f(%v)", x) }
+			actual := Map(wrap, xss, 2)
+			Expect(actual).To(Equal([]interface{}{"f([f(1) f(2)])", "f([f(3)])"}))
+		})
+
+		It("maps at levels m through n.", func() {
+			actual := Map(func(xs []int) int { return len(xs) }, [][][]int{{{1}, {2, 3}}}, []int{2, 2})
+			Expect(actual).To(Equal([][]int{{1, 2}}))
+		})
+
+		It("panics when f doesn't accept a part in the levels.", func() {
+			Ω(func() { Map(strconv.Itoa, xss, 2) }).Should(Panic())
+			Ω(func() { Map(strconv.Itoa, []int{1}, []int{2}) }).Should(Panic())
+			Ω(func() { Map(strconv.Itoa, xss, "2") }).Should(Panic())
+		})
+	})
+
+	Context("MapAll(f, expr)", func() {
+		It("applies f to every part it accepts.", func() {
+			wrap := func(x interface{}) interface{} { return fmt.Sprintf("f(%v)", x) }
+			actual := MapAll(wrap, [][]int{{1}, {2, 3}})
+			Expect(actual).To(Equal("f([f([f(1)]) f([f(2) f(3)])])"))
+		})
+
+		It("skips parts f doesn't accept.", func() {
+			actual := MapAll(func(x int) int { return x * 10 }, [][]int{{1}, {2, 3}})
+			Expect(actual).To(Equal([][]int{{10}, {20, 30}}))
+		})
+	})
+
+	Context("Scan(f, list)", func() {
+		It("visits each element.", func() {
+			total := 0
+			actual := Scan(func(x int) int { total += x; return total }, Range(4))
+			Expect(actual).To(BeNil())
+			Expect(total).To(Equal(10))
+		})
+
+		It("stops at Throw and gives the thrown value.", func() {
+			visited := []int{}
+			actual := Scan(func(x int) {
+				visited = append(visited, x)
+				if x > 2 {
+					Throw(x * 100)
+				}
+			}, Range(5))
+			Expect(actual).To(Equal(300))
+			Expect(visited).To(Equal([]int{1, 2, 3}))
+		})
+
+		It("lets other panics through.", func() {
+			Ω(func() { Scan(func(x int) { panic("boom") }, Range(1)) }).Should(PanicWith("boom"))
+		})
+	})
+})
@@ -0,0 +1,48 @@
+package test
+
+import (
+	. "fp"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"math"
+	"strings"
+)
+
+var _ = Describe("listable", func() {
+	Context("Listable(f)", func() {
+		It("threads f through nested lists.", func() {
+			sqrt := Listable(math.Sqrt)
+			Expect(sqrt(4.0)).To(Equal(2.0))
+			Expect(sqrt([]float64{1, 4})).To(Equal([]float64{1, 2}))
+			Expect(sqrt([][]float64{{1, 4}, {9}})).To(Equal([][]float64{{1, 2}, {3}}))
+			Expect(sqrt([]float64{})).To(Equal([]float64{}))
+		})
+
+		It("broadcasts other arguments.", func() {
+			repeat := Listable(strings.Repeat)
+			Expect(repeat([]string{"a", "b"}, 2)).To(Equal([]string{"aa", "bb"}))
+			Expect(repeat("a", [2]int{1, 3})).To(Equal([]string{"a", "aaa"}))
+			Expect(repeat([]string{"a", "b"}, []int{3, 1})).To(Equal([]string{"aaa", "b"}))
+		})
+
+		It("gives []interface{} for results of different types.", func() {
+			half := Listable(func(x int) interface{} {
+				if x%2 == 0 {
+					return x / 2
+				}
+				return float64(x) / 2
+			})
+			Expect(half([]int{2, 3})).To(Equal([]interface{}{1, 1.5}))
+		})
+
+		It("panics on bad arguments.", func() {
+			sqrt := Listable(math.Sqrt)
+			Ω(func() { sqrt([]int{1}) }).Should(Panic())
+			Ω(func() { sqrt(1.0, 2.0) }).Should(Panic())
+			Ω(func() { Listable(42) }).Should(Panic())
+			Ω(func() { Listable(func(xs ...int) int { return 0 }) }).Should(Panic())
+			repeat := Listable(strings.Repeat)
+			Ω(func() { repeat([][]string{{"a"}}, [][]int{{1, 2}}) }).Should(PanicWith("Listable: lists of shapes [1 1] and [1 2] can't be combined; their lengths differ at part [1]."))
+		})
+	})
+})
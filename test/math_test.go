@@ -178,6 +178,12 @@ var _ = Describe("files", func() {
 		It("string", func() {
 			Ω(func(){Abs("abc")}).Should(Panic())
 		})
+
+		It("slice", func() {
+			Expect(Abs([]int{-1, 2})).To(Equal([]int{1, 2}))
+			Expect(Abs([2][]float64{{-1.5}, {2, -3}})).To(Equal([][]float64{{1.5}, {2, 3}}))
+			Expect(Abs([]interface{}{-1, int8(-2)})).To(Equal([]interface{}{1, int8(2)}))
+		})
 	})
 
 	Context("Pow(x, y)", func() {
@@ -260,5 +266,18 @@ var _ = Describe("files", func() {
 		It("string", func() {
 			Ω(func(){Pow("abc", 2)}).Should(Panic())
 		})
+
+		It("slice", func() {
+			xs := []int{1, 2, 3}
+			Expect(Pow(xs, 2)).To(Equal([]int{1, 4, 9}))
+			Expect(Pow(2, xs)).To(Equal([]int{2, 4, 8}))
+			Expect(Pow(xs, []int{3, 2, 1})).To(Equal([]int{1, 4, 3}))
+			Expect(Pow([][]float64{{1, 2}, {3, 4}}, []float64{2, 0.5})).To(Equal([][]float64{{1, 4}, {1.7320508075688772, 2}}))
+		})
+
+		It("shape mismatch", func() {
+			Ω(func(){Pow([]int{1, 2}, []int{1, 2, 3})}).Should(PanicWith("Pow: lists of shapes [2] and [3] can't be combined; their lengths differ."))
+			Ω(func(){Pow([][]int{{1, 2}, {3}}, [][]int{{1, 2}, {3, 4}})}).Should(PanicWith("Pow: lists of shapes [2] and [2 2] can't be combined; their lengths differ at part [2]."))
+		})
 	})
 })
\ No newline at end of file
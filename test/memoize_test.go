@@ -0,0 +1,251 @@
+package test
+
+import (
+	"errors"
+	. "fp"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type fakeTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+// fakeClock only moves when Advance or Sleep is called.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []fakeTimer
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	c.sleeps = append(c.sleeps, d)
+	c.mu.Unlock()
+	c.Advance(d)
+}
+
+func (c *fakeClock) Sleeps() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]time.Duration{}, c.sleeps...)
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	c.timers = append(c.timers, fakeTimer{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+func (c *fakeClock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.at.After(c.now) {
+			pending = append(pending, t)
+		} else {
+			t.ch <- c.now
+		}
+	}
+	c.timers = pending
+}
+
+var _ = Describe("memoize", func() {
+	var calls int64
+	square := func(x int) int {
+		atomic.AddInt64(&calls, 1)
+		return x * x
+	}
+
+	BeforeEach(func() {
+		atomic.StoreInt64(&calls, 0)
+	})
+
+	Context("Memoize(f)", func() {
+		It("evaluates f once for the same arguments.", func() {
+			f := Memoize(square).(func(int) int)
+			Expect(f(3)).To(Equal(9))
+			Expect(f(3)).To(Equal(9))
+			Expect(f(4)).To(Equal(16))
+			Expect(calls).To(Equal(int64(2)))
+		})
+
+		It("treats equal slices and maps as the same arguments.", func() {
+			total := func(xs []int, weights map[string]int) int {
+				atomic.AddInt64(&calls, 1)
+				return Fold(func(r, x int) int { return r + x }, weights["a"], xs).(int)
+			}
+			f := Memoize(total).(func([]int, map[string]int) int)
+			Expect(f([]int{1, 2}, map[string]int{"a": 1, "b": 2})).To(Equal(4))
+			Expect(f([]int{1, 2}, map[string]int{"b": 2, "a": 1})).To(Equal(4))
+			Expect(f([]int{2, 1}, map[string]int{"a": 1, "b": 2})).To(Equal(4))
+			Expect(calls).To(Equal(int64(2)))
+		})
+
+		It("memoizes variadic functions.", func() {
+			join := func(sep string, xs ...string) string {
+				atomic.AddInt64(&calls, 1)
+				return strings.Join(xs, sep)
+			}
+			f := Memoize(join).(func(string, ...string) string)
+			Expect(f("-", "a", "b")).To(Equal("a-b"))
+			Expect(f("-", "a", "b")).To(Equal("a-b"))
+			Expect(calls).To(Equal(int64(1)))
+		})
+
+		It("doesn't cache results with an error.", func() {
+			parse := func(s string) (int, error) {
+				atomic.AddInt64(&calls, 1)
+				return 0, errors.New("bad input")
+			}
+			f := Memoize(parse).(func(string) (int, error))
+			_, err := f("x")
+			Expect(err).To(HaveOccurred())
+			_, err = f("x")
+			Expect(err).To(HaveOccurred())
+			Expect(calls).To(Equal(int64(2)))
+		})
+
+		It("doesn't cache results with an error of another error interface.", func() {
+			type failure interface{ error }
+			parse := func(s string) (int, failure) {
+				atomic.AddInt64(&calls, 1)
+				return 0, errors.New("bad input")
+			}
+			f := Memoize(parse).(func(string) (int, failure))
+			f("x")
+			_, err := f("x")
+			Expect(err).To(HaveOccurred())
+			Expect(calls).To(Equal(int64(2)))
+		})
+
+		It("panics when f isn't a function.", func() {
+			Ω(func() { Memoize(1) }).Should(Panic())
+		})
+	})
+
+	Context("NewMemo(f, MemoizeOptions{MaxSize: n})", func() {
+		It("evicts the least recently used result.", func() {
+			m := NewMemo(square, MemoizeOptions{MaxSize: 2, Policy: LRU})
+			f := m.Func().(func(int) int)
+			f(1)
+			f(2)
+			f(1)
+			f(3)
+			f(1)
+			f(2)
+			Expect(calls).To(Equal(int64(4)))
+			stats := m.Stats()
+			Expect(stats.Hits).To(Equal(uint64(2)))
+			Expect(stats.Misses).To(Equal(uint64(4)))
+			Expect(stats.Evictions).To(Equal(uint64(2)))
+			Expect(stats.Size).To(Equal(2))
+		})
+
+		It("evicts the least frequently used result.", func() {
+			m := NewMemo(square, MemoizeOptions{MaxSize: 2, Policy: LFU})
+			f := m.Func().(func(int) int)
+			f(1)
+			f(1)
+			f(2)
+			f(3)
+			f(1)
+			f(2)
+			Expect(calls).To(Equal(int64(4)))
+			Expect(m.Stats().Hits).To(Equal(uint64(2)))
+		})
+
+		It("forgets everything after Purge.", func() {
+			m := NewMemo(square)
+			f := m.Func().(func(int) int)
+			f(1)
+			m.Purge()
+			f(1)
+			Expect(calls).To(Equal(int64(2)))
+			Expect(m.Stats().Size).To(Equal(1))
+		})
+	})
+
+	Context("NewMemo(f, MemoizeOptions{TTL: d})", func() {
+		It("expires results after d.", func() {
+			clock := &fakeClock{now: time.Unix(0, 0)}
+			m := NewMemo(square, MemoizeOptions{TTL: time.Minute, Clock: clock})
+			f := m.Func().(func(int) int)
+			f(2)
+			clock.Advance(30 * time.Second)
+			f(2)
+			clock.Advance(30 * time.Second)
+			f(2)
+			Expect(calls).To(Equal(int64(2)))
+			Expect(m.Stats().Expirations).To(Equal(uint64(1)))
+		})
+
+		It("drops expired results which aren't looked up again.", func() {
+			clock := &fakeClock{now: time.Unix(0, 0)}
+			m := NewMemo(square, MemoizeOptions{TTL: time.Minute, Clock: clock})
+			f := m.Func().(func(int) int)
+			for i := 0; i < 100; i++ {
+				f(i)
+			}
+			clock.Advance(time.Minute)
+			f(100)
+			Expect(m.Stats().Size).To(Equal(1))
+			Expect(m.Stats().Expirations).To(Equal(uint64(100)))
+		})
+	})
+
+	Context("NewMemo(f, MemoizeOptions{SingleFlight: true})", func() {
+		It("shares one evaluation among concurrent callers.", func() {
+			release := make(chan struct{})
+			slow := func(x int) int {
+				atomic.AddInt64(&calls, 1)
+				<-release
+				return x * x
+			}
+			m := NewMemo(slow, MemoizeOptions{SingleFlight: true})
+			f := m.Func().(func(int) int)
+
+			results := make(chan int, 8)
+			for i := 0; i < 8; i++ {
+				go func() { results <- f(5) }()
+			}
+			Eventually(func() uint64 { s := m.Stats(); return s.Misses + s.Shared }).Should(Equal(uint64(8)))
+			close(release)
+			for i := 0; i < 8; i++ {
+				Expect(<-results).To(Equal(25))
+			}
+			Expect(calls).To(Equal(int64(1)))
+		})
+
+		It("is safe to use from ParallelMap workers.", func() {
+			f := Memoize(square, MemoizeOptions{MaxSize: 4, SingleFlight: true}).(func(int) int)
+			xs := make([]int, 200)
+			for i := range xs {
+				xs[i] = i % 6
+			}
+			Expect(ParallelMap(f, xs)).To(Equal(Map(func(x int) int { return x * x }, xs)))
+		})
+	})
+})
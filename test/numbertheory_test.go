@@ -0,0 +1,132 @@
+package test
+
+import (
+	. "fp"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"math/big"
+)
+
+var _ = Describe("number theory", func() {
+	bigInt := func(s string) *big.Int {
+		z, _ := new(big.Int).SetString(s, 10)
+		return z
+	}
+
+	Context("Prime(n), PrimePi(x), NextPrime(n) and Primes(bounds...)", func() {
+		It("gives primes in the type of the argument.", func() {
+			Expect(Prime(1)).To(Equal(2))
+			Expect(Prime(int64(100))).To(Equal(int64(541)))
+			Expect(Prime(uint16(1000))).To(Equal(uint16(7919)))
+			Expect(PrimePi(100)).To(Equal(25))
+			Expect(PrimePi(1)).To(Equal(0))
+			Expect(NextPrime(-5)).To(Equal(2))
+			Expect(NextPrime(7)).To(Equal(11))
+			Expect(NextPrime(uint64(1) << 62)).To(Equal(uint64(1)<<62 + 135))
+			Expect(NextPrime(bigInt("100000000000000000000"))).To(Equal(bigInt("100000000000000000039")))
+			Expect(Primes(20)).To(Equal([]int{2, 3, 5, 7, 11, 13, 17, 19}))
+			Expect(Primes(10, int8(30))).To(Equal([]int8{11, 13, 17, 19, 23, 29}))
+		})
+
+		It("sieves large bounds in segments.", func() {
+			Expect(PrimePi(10000000)).To(Equal(664579))
+			Expect(Prime(1000000)).To(Equal(15485863))
+			// The range crosses the boundary of two segments.
+			expected := Filter(func(n int) bool { return PrimeQ(n) }, Range(65500, 65600))
+			Expect(Primes(65500, 65600)).To(Equal(expected))
+			expected = Filter(func(n int) bool { return PrimeQ(n) }, Range(2000000000, 2000000100))
+			Expect(Primes(2000000000, 2000000100)).To(Equal(expected))
+			Expect(Primes(50, 40)).To(Equal([]int{}))
+		})
+
+		It("panics when the result doesn't fit.", func() {
+			Ω(func() { Prime(int8(100)) }).Should(Panic())
+			Ω(func() { Prime(0) }).Should(Panic())
+			Ω(func() { Prime(1.5) }).Should(Panic())
+		})
+	})
+
+	Context("PrimeQ(n)", func() {
+		It("tests primality.", func() {
+			Expect(Filter(func(n int) bool { return PrimeQ(n) }, Range(-3, 30))).To(Equal(Primes(30)))
+			Expect(PrimeQ(uint64(18446744073709551557))).To(BeTrue())
+			// Strong pseudoprimes to the bases 2 to 37.
+			Expect(PrimeQ(uint64(3825123056546413051))).To(BeFalse())
+			Expect(PrimeQ(bigInt("318665857834031151167461"))).To(BeFalse())
+			Expect(PrimeQ(bigInt("170141183460469231731687303715884105727"))).To(BeTrue())
+		})
+	})
+
+	Context("FactorInteger(n), Divisors(n), EulerPhi(n) and MoebiusMu(n)", func() {
+		It("factors integers.", func() {
+			Expect(FactorInteger(360)).To(Equal([][2]int{{2, 3}, {3, 2}, {5, 1}}))
+			Expect(FactorInteger(int32(-7))).To(Equal([][2]int32{{-1, 1}, {7, 1}}))
+			Expect(FactorInteger(1)).To(Equal([][2]int{{1, 1}}))
+			Expect(FactorInteger(uint64(18446744030759878681))).To(Equal([][2]uint64{{4294967291, 2}}))
+			Expect(FactorInteger(int64(999999000001) * 1000003)).To(Equal([][2]int64{{1000003, 1}, {999999000001, 1}}))
+			factors := FactorInteger(bigInt("1000000016000000063")).([][2]*big.Int)
+			Expect(factors).To(HaveLen(2))
+			Expect(factors[0][0]).To(Equal(bigInt("1000000007")))
+			Expect(factors[1][0]).To(Equal(bigInt("1000000009")))
+		})
+
+		It("gives the divisors.", func() {
+			Expect(Divisors(12)).To(Equal([]int{1, 2, 3, 4, 6, 12}))
+			Expect(Divisors(uint8(49))).To(Equal([]uint8{1, 7, 49}))
+			Expect(Divisors(-1)).To(Equal([]int{1}))
+			Ω(func() { Divisors(0) }).Should(Panic())
+		})
+
+		It("gives the multiplicative functions.", func() {
+			Expect(EulerPhi(1)).To(Equal(1))
+			Expect(EulerPhi(uint(36))).To(Equal(uint(12)))
+			Expect(EulerPhi(bigInt("1000000016000000063"))).To(Equal(bigInt("1000000014000000048")))
+			Expect(MoebiusMu(1)).To(Equal(1))
+			Expect(MoebiusMu(30)).To(Equal(-1))
+			Expect(MoebiusMu(int8(12))).To(Equal(0))
+		})
+	})
+
+	Context("GCD(args...), LCM(args...) and ExtendedGCD(a, b)", func() {
+		It("gives the common divisors and multiples.", func() {
+			Expect(GCD(12, 18, -8)).To(Equal(2))
+			Expect(GCD(uint(0), 5)).To(Equal(uint(5)))
+			Expect(GCD()).To(Equal(0))
+			Expect(LCM(4, 6, int64(10))).To(Equal(60))
+			Expect(LCM(3, 0)).To(Equal(0))
+			Ω(func() { LCM(int8(100), 3) }).Should(Panic())
+		})
+
+		It("gives the Bezout coefficients.", func() {
+			g, x, y := ExtendedGCD(240, -46)
+			Expect(g).To(Equal(2))
+			Expect(240*x.(int) - 46*y.(int)).To(Equal(2))
+			g, x, y = ExtendedGCD(uint(3), uint(5))
+			Expect(g).To(Equal(uint(1)))
+			Expect(x).To(Equal(big.NewInt(2)))
+			Expect(y).To(Equal(big.NewInt(-1)))
+			g, x, y = ExtendedGCD(int8(-128), int8(127))
+			Expect(g).To(Equal(int8(1)))
+			Expect(-128*int(x.(int8)) + 127*int(y.(int8))).To(Equal(1))
+		})
+	})
+
+	Context("PowerMod(a, b, m), ModularInverse(a, m) and ChineseRemainder(remainders, moduli)", func() {
+		It("computes modulo m.", func() {
+			Expect(PowerMod(2, 10, 1000)).To(Equal(24))
+			Expect(PowerMod(-2, 3, 5)).To(Equal(2))
+			Expect(PowerMod(3, -1, 7)).To(Equal(5))
+			// Fermat's little theorem near the top of uint64.
+			Expect(PowerMod(uint64(2), uint64(18446744073709551556), uint64(18446744073709551557))).To(Equal(uint64(1)))
+			Expect(ModularInverse(3, 11)).To(Equal(4))
+			Ω(func() { ModularInverse(2, 4) }).Should(Panic())
+			Ω(func() { PowerMod(2, 3, 0) }).Should(Panic())
+		})
+
+		It("solves simultaneous congruences.", func() {
+			Expect(ChineseRemainder([]int{2, 3, 2}, []int{3, 5, 7})).To(Equal(23))
+			Expect(ChineseRemainder([]int64{1, 3}, []int{4, 6})).To(Equal(int64(9)))
+			Ω(func() { ChineseRemainder([]int{1, 2}, []int{4, 6}) }).Should(Panic())
+		})
+	})
+})
@@ -0,0 +1,133 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	. "fp"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"strconv"
+)
+
+var _ = Describe("option", func() {
+	double := func(x int) int { return x * 2 }
+	positiveQ := func(x int) bool { return x > 0 }
+
+	Context("Option", func() {
+		It("maps and filters Some.", func() {
+			Expect(Some(3).Map(double).Unwrap()).To(Equal(6))
+			Expect(Some(3).Filter(positiveQ).IsSome()).To(BeTrue())
+			Expect(Some(-3).Filter(positiveQ).IsNone()).To(BeTrue())
+		})
+
+		It("leaves None alone.", func() {
+			Expect(None().Map(double).IsNone()).To(BeTrue())
+			Expect(None().UnwrapOr(0)).To(Equal(0))
+			Expect(None().OrElse(Some(1))).To(Equal(Some(1)))
+			Ω(func() { None().Unwrap() }).Should(Panic())
+		})
+
+		It("flat maps functions giving Option or (T, bool).", func() {
+			half := func(x int) Option { return OptionOf(x/2, x%2 == 0) }
+			lookup := func(x int) (string, bool) {
+				m := map[int]string{2: "two"}
+				s, ok := m[x]
+				return s, ok
+			}
+			Expect(Some(4).FlatMap(half).Bind(lookup)).To(Equal(Some("two")))
+			Expect(Some(3).FlatMap(half).Bind(lookup).IsNone()).To(BeTrue())
+		})
+
+		It("converts to and from (T, bool).", func() {
+			value, ok := OptionOf(1, true).Get()
+			Expect(value).To(Equal(1))
+			Expect(ok).To(BeTrue())
+			Expect(OptionOf(1, false).IsNone()).To(BeTrue())
+		})
+
+		It("panics when f doesn't accept the value.", func() {
+			Ω(func() { Some("a").Map(double) }).Should(Panic())
+		})
+
+		It("marshals to JSON.", func() {
+			data, err := json.Marshal([]Option{Some(1), None()})
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(Equal("[1,null]"))
+
+			var options []Option
+			Expect(json.Unmarshal(data, &options)).To(Succeed())
+			Expect(options).To(Equal([]Option{Some(1.0), None()}))
+
+			typed := Some(0)
+			Expect(json.Unmarshal([]byte("42"), &typed)).To(Succeed())
+			Expect(typed).To(Equal(Some(42)))
+		})
+	})
+
+	Context("Result", func() {
+		It("maps functions giving T or (T, error).", func() {
+			Expect(Ok(3).Map(double).Unwrap()).To(Equal(6))
+			Expect(Ok("12").Map(strconv.Atoi).Map(double).Unwrap()).To(Equal(24))
+			Expect(Ok("x").Map(strconv.Atoi).Map(double).IsErr()).To(BeTrue())
+		})
+
+		It("flat maps functions giving Result or (T, error).", func() {
+			parse := func(s string) Result { return ResultOf(strconv.Atoi(s)) }
+			Expect(Ok("7").FlatMap(parse).Map(strconv.Itoa).Unwrap()).To(Equal("7"))
+			Expect(Ok("7").Bind(parse).Unwrap()).To(Equal(7))
+		})
+
+		It("maps functions giving other error interfaces.", func() {
+			type failure interface{ error }
+			parse := func(s string) (int, failure) { return strconv.Atoi(s) }
+			Expect(Ok("12").Map(parse).Unwrap()).To(Equal(12))
+			Expect(Ok("x").FlatMap(parse).IsErr()).To(BeTrue())
+		})
+
+		It("keeps the first error.", func() {
+			failure := errors.New("failure")
+			r := Err(failure).Map(double).Filter(positiveQ)
+			Expect(r.Err()).To(Equal(failure))
+			Expect(r.UnwrapOr(0)).To(Equal(0))
+			Expect(r.OrElse(Ok(1)).Unwrap()).To(Equal(1))
+			Ω(func() { r.Unwrap() }).Should(Panic())
+		})
+
+		It("fails with ErrFiltered.", func() {
+			Expect(Ok(-1).Filter(positiveQ).Err()).To(Equal(ErrFiltered))
+		})
+
+		It("converts to and from (T, error) and Option.", func() {
+			value, err := ResultOf(1, nil).Get()
+			Expect(value).To(Equal(1))
+			Expect(err).To(BeNil())
+			Expect(Ok(1).Option()).To(Equal(Some(1)))
+			Expect(None().OkOr(ErrFiltered).Err()).To(Equal(ErrFiltered))
+			Ω(func() { Err(nil) }).Should(Panic())
+		})
+
+		It("marshals to JSON.", func() {
+			data, err := json.Marshal([]Result{Ok(1), Err(errors.New("bad"))})
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(Equal(`[{"ok":1},{"error":"bad"}]`))
+
+			var results []Result
+			Expect(json.Unmarshal(data, &results)).To(Succeed())
+			Expect(results[0].Unwrap()).To(Equal(1.0))
+			Expect(results[1].Err()).To(MatchError("bad"))
+		})
+	})
+
+	Context("FirstOption(slice), LastOption(slice) and LookupOption(a, key)", func() {
+		It("gives None instead of panicking.", func() {
+			Expect(FirstOption([]int{1, 2})).To(Equal(Some(1)))
+			Expect(LastOption([]int{1, 2})).To(Equal(Some(2)))
+			Expect(FirstOption([]int{}).IsNone()).To(BeTrue())
+			Expect(LastOption([]int{}).IsNone()).To(BeTrue())
+
+			a := NewAssociation(Rule{Key: "a", Value: 1})
+			Expect(LookupOption(a, "a")).To(Equal(Some(1)))
+			Expect(LookupOption(a, "b").IsNone()).To(BeTrue())
+		})
+	})
+})
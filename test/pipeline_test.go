@@ -0,0 +1,143 @@
+package test
+
+import (
+	"context"
+	. "fp"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"strconv"
+	"strings"
+)
+
+var _ = Describe("pipeline", func() {
+	ctx := context.Background()
+	square := func(x int) int { return x * x }
+	evenQ := func(x int) bool { return x%2 == 0 }
+	sum := func(r, x int) int { return r + x }
+
+	Context("NewPipeline(sample)", func() {
+		It("runs stages in order on a slice.", func() {
+			p := NewPipeline(0).Filter(evenQ).Map(square)
+			Expect(p.Run(Range(6))).To(Equal([]int{4, 16, 36}))
+		})
+
+		It("runs on an array.", func() {
+			p := NewPipeline(0).Map(strconv.Itoa)
+			Expect(p.Run([3]int{1, 2, 3})).To(Equal([]string{"1", "2", "3"}))
+		})
+
+		It("runs an empty pipeline.", func() {
+			Expect(NewPipeline(0).Run([]int{})).To(Equal([]int{}))
+		})
+
+		It("gives the result of Fold.", func() {
+			p := NewPipeline(0).Map(square).Fold(sum, 0)
+			Expect(p.Run(Range(3))).To(Equal(14))
+		})
+
+		It("folds with a combinator which gives interface{}.", func() {
+			Expect(NewPipeline(0).Fold(Plus, 0).Run([]int{1, 2, 3})).To(Equal(6))
+		})
+
+		It("gives the groups of GroupBy.", func() {
+			p := NewPipeline("").Map(strings.ToLower).GroupBy(func(s string) int { return len(s) })
+			groups := p.Run([]string{"Go", "Fun", "is", "FP"}).(*Association)
+			Expect(groups.Keys()).To(Equal([]interface{}{2, 3}))
+			Expect(groups.Values()).To(Equal([]interface{}{[]string{"go", "is", "fp"}, []string{"fun"}}))
+		})
+
+		It("sorts in canonical order or with less.", func() {
+			xs := []int{3, 1, 2}
+			Expect(NewPipeline(0).Sort().Run(xs)).To(Equal([]int{1, 2, 3}))
+			Expect(NewPipeline(0).Sort(func(a, b int) bool { return a > b }).Run(xs)).To(Equal([]int{3, 2, 1}))
+		})
+	})
+
+	Context("stage validation", func() {
+		It("panics when a stage doesn't accept the previous output.", func() {
+			Ω(func() { NewPipeline(0).Map(strconv.Itoa).Map(square) }).Should(Panic())
+		})
+
+		It("panics when Fold's accumulator doesn't match its initial value.", func() {
+			Ω(func() { NewPipeline(0).Fold(sum, "") }).Should(Panic())
+			Ω(func() { NewPipeline(0).Fold(func(r, x int) string { return "" }, 0) }).Should(Panic())
+		})
+
+		It("panics when a stage follows Fold.", func() {
+			Ω(func() { NewPipeline(0).Fold(sum, 0).Map(square) }).Should(Panic())
+		})
+
+		It("panics when Sort has no ordering function for a struct.", func() {
+			type Person struct{ name string }
+			Ω(func() { NewPipeline(Person{}).Sort() }).Should(Panic())
+		})
+
+		It("panics when the source has another element type.", func() {
+			Ω(func() { NewPipeline(0).Map(square).Run([]string{"a"}) }).Should(Panic())
+		})
+	})
+
+	Context("Stream(ctx, source) and Collect(ctx, source)", func() {
+		It("streams a channel.", func() {
+			p := NewPipeline(0).Filter(evenQ).Map(strconv.Itoa)
+			out := p.Stream(ctx, source(1, 2, 3, 4)).(<-chan string)
+			actual := []string{}
+			for s := range out {
+				actual = append(actual, s)
+			}
+			Expect(actual).To(Equal([]string{"2", "4"}))
+		})
+
+		It("streams a generator.", func() {
+			n := 0
+			next := func() (int, bool) {
+				n++
+				return n, n <= 5
+			}
+			p := NewPipeline(0).Map(square).Sort(func(a, b int) bool { return a > b })
+			Expect(drain(p.Stream(ctx, next).(<-chan int))).To(Equal([]int{25, 16, 9, 4, 1}))
+		})
+
+		It("collects the result of Fold.", func() {
+			p := NewPipeline(0).Map(square).Fold(sum, 0)
+			actual, err := p.Collect(ctx, source(1, 2, 3))
+			Expect(err).To(BeNil())
+			Expect(actual).To(Equal(14))
+		})
+
+		It("collects the result of Fold with Plus.", func() {
+			actual, err := NewPipeline(0).Fold(Plus, 0).Collect(ctx, source(1, 2, 3))
+			Expect(err).To(BeNil())
+			Expect(actual).To(Equal(6))
+		})
+
+		It("collects a slice.", func() {
+			p := NewPipeline(0).Sort()
+			actual, err := p.Collect(ctx, []int{3, 1, 2})
+			Expect(err).To(BeNil())
+			Expect(actual).To(Equal([]int{1, 2, 3}))
+		})
+
+		It("gives the error of ctx when cancelled.", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, err := NewPipeline(0).Map(square).Collect(ctx, make(chan int))
+			Expect(err).To(Equal(context.Canceled))
+		})
+
+		It("panics when Stream is used on a pipeline ending with Fold.", func() {
+			Ω(func() { NewPipeline(0).Fold(sum, 0).Stream(ctx, source()) }).Should(Panic())
+		})
+	})
+
+	Context("String()", func() {
+		It("describes the plan.", func() {
+			p := NewPipeline(0).Filter(evenQ).Map(strconv.Itoa).Sort()
+			expected := "Pipeline of int\n" +
+				"  1. Filter(func (int) bool): int -> int\n" +
+				"  2. Map(func (int) string): int -> string\n" +
+				"  3. Sort(canonical order): string -> string"
+			Expect(p.String()).To(Equal(expected))
+		})
+	})
+})
@@ -0,0 +1,132 @@
+package test
+
+import (
+	. "fp"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("random", func() {
+	AfterEach(func() {
+		SeedRandom()
+	})
+
+	Context("SeedRandom(seed...)", func() {
+		It("repeats the values of the global state.", func() {
+			SeedRandom(42)
+			xs := RandomInteger(100, 10)
+			ys := RandomReal(nil, 3)
+			SeedRandom(42)
+			Expect(RandomInteger(100, 10)).To(Equal(xs))
+			Expect(RandomReal(nil, 3)).To(Equal(ys))
+		})
+
+		It("repeats the values of an explicit state.", func() {
+			s1, s2 := NewRandomState(7), NewRandomState(7)
+			Expect(s1.RandomSample(Range(20))).To(Equal(s2.RandomSample(Range(20))))
+			Expect(s1.RandomPermutation(10)).To(Equal(s2.RandomPermutation(10)))
+		})
+	})
+
+	Context("RandomInteger(spec, dims...)", func() {
+		It("keeps the type and range of spec.", func() {
+			SeedRandom(1)
+			for i := 0; i < 100; i++ {
+				Expect(RandomInteger(3)).To(BeNumerically(">=", 0))
+				Expect(RandomInteger(3)).To(BeNumerically("<=", 3))
+				x := RandomInteger([]int8{-5, -2}).(int8)
+				Expect(x >= -5 && x <= -2).To(BeTrue())
+			}
+			Expect(RandomInteger(uint16(9))).To(BeAssignableToTypeOf(uint16(0)))
+			Expect(RandomInteger([]int{4, 4})).To(Equal(4))
+		})
+
+		It("gives every integer of the range.", func() {
+			SeedRandom(1)
+			xs := RandomInteger([]int{1, 6}, 600).([]int)
+			Expect(Sort(Union(xs))).To(Equal([]int{1, 2, 3, 4, 5, 6}))
+		})
+
+		It("gives nested lists with dims.", func() {
+			xs := RandomInteger(9, 2, 3).([][]int)
+			Expect(xs).To(HaveLen(2))
+			Expect(xs[0]).To(HaveLen(3))
+			Expect(xs[1]).To(HaveLen(3))
+		})
+
+		It("panics on other specs.", func() {
+			Ω(func() { RandomInteger(1.5) }).Should(Panic())
+			Ω(func() { RandomInteger([]int{1, 2, 3}) }).Should(Panic())
+			Ω(func() { RandomInteger(1, -1) }).Should(PanicWith("RandomInteger: [-1] should be non-negative dimensions."))
+			Ω(func() { NewRandomState(1).RandomReal(nil, 2, -1) }).Should(PanicWith("RandomReal: [2 -1] should be non-negative dimensions."))
+		})
+	})
+
+	Context("RandomReal(spec, dims...) and RandomComplex(spec, dims...)", func() {
+		It("gives values in the range of spec.", func() {
+			SeedRandom(2)
+			for _, x := range RandomReal([]float64{-2, -1}, 100).([]float64) {
+				Expect(x >= -2 && x < -1).To(BeTrue())
+			}
+			Expect(RandomReal(float32(2))).To(BeAssignableToTypeOf(float32(0)))
+			for _, z := range RandomComplex([]complex128{1, 2 + 3i}, 100).([]complex128) {
+				Expect(real(z) >= 1 && real(z) < 2 && imag(z) >= 0 && imag(z) < 3).To(BeTrue())
+			}
+			Expect(RandomComplex(nil)).To(BeAssignableToTypeOf(0i))
+		})
+	})
+
+	Context("RandomChoice(list, n, weights...)", func() {
+		It("chooses with replacement.", func() {
+			SeedRandom(3)
+			xs := RandomChoice([]string{"a", "b"}, 100).([]string)
+			Expect(xs).To(HaveLen(100))
+			Expect(Sort(Union(xs))).To(Equal([]string{"a", "b"}))
+		})
+
+		It("follows the weights.", func() {
+			SeedRandom(3)
+			xs := RandomChoice([]string{"a", "b", "c"}, 1000, []int{1, 0, 3}).([]string)
+			Expect(xs).NotTo(ContainElement("b"))
+			Expect(Count(xs, "c")).To(BeNumerically(">", 650))
+		})
+
+		It("panics on bad weights.", func() {
+			Ω(func() { RandomChoice([]int{1, 2}, 1, []int{1}) }).Should(Panic())
+			Ω(func() { RandomChoice([]int{1, 2}, 1, []int{0, 0}) }).Should(Panic())
+			Ω(func() { RandomChoice([]int{1, 2}, 1, []int{-1, 2}) }).Should(Panic())
+			Ω(func() { RandomChoice([]int{}, 1) }).Should(Panic())
+		})
+	})
+
+	Context("RandomSample(list, n...) and RandomPermutation(n)", func() {
+		It("chooses without replacement.", func() {
+			SeedRandom(4)
+			xs := RandomSample(Range(10), 5).([]int)
+			Expect(xs).To(HaveLen(5))
+			Expect(DeleteDuplicates(xs)).To(HaveLen(5))
+			Expect(Sort(RandomSample(Range(10)))).To(Equal(Range(10)))
+			Ω(func() { RandomSample(Range(3), 4) }).Should(Panic())
+		})
+
+		It("permutes Range(n).", func() {
+			Expect(Sort(RandomPermutation(10))).To(Equal(Range(10)))
+			Expect(RandomPermutation(0)).To(BeEmpty())
+		})
+	})
+
+	Context("CryptoRandomState()", func() {
+		It("draws from crypto/rand.", func() {
+			s := CryptoRandomState()
+			x := s.RandomInteger([]int{10, 20}).(int)
+			Expect(x >= 10 && x <= 20).To(BeTrue())
+			Expect(Sort(s.RandomPermutation(5))).To(Equal(Range(5)))
+			Expect(s.RandomVariate(NormalDistribution(0, 1), 3)).To(HaveLen(3))
+		})
+
+		It("can be the global state.", func() {
+			SetRandomState(CryptoRandomState())
+			Expect(RandomReal(nil)).To(BeNumerically("<", 1))
+		})
+	})
+})
@@ -0,0 +1,86 @@
+package test
+
+import (
+	. "fp"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"math"
+	"math/big"
+)
+
+var _ = Describe("rational", func() {
+	Context("Numerator(x) and Denominator(x)", func() {
+		It("gives the parts in lowest terms.", func() {
+			Expect(Numerator(big.NewRat(6, -4))).To(Equal(big.NewInt(-3)))
+			Expect(Denominator(big.NewRat(6, -4))).To(Equal(big.NewInt(2)))
+			Expect(Numerator(int8(5))).To(Equal(int8(5)))
+			Expect(Denominator(uint(5))).To(Equal(uint(1)))
+			Ω(func() { Numerator(0.5) }).Should(Panic())
+		})
+	})
+
+	Context("Rationalize(x, tol...)", func() {
+		It("gives the simplest rational which rounds to x.", func() {
+			Expect(Rationalize(0.1)).To(Equal(big.NewRat(1, 10)))
+			Expect(Rationalize(-2.5)).To(Equal(big.NewRat(-5, 2)))
+			Expect(Rationalize(float32(1) / 3)).To(Equal(big.NewRat(1, 3)))
+			Expect(Rationalize(1.0 / 3)).To(Equal(big.NewRat(1, 3)))
+			Expect(Rationalize(7)).To(Equal(big.NewRat(7, 1)))
+		})
+
+		It("gives the simplest rational within tol.", func() {
+			Expect(Rationalize(math.Pi, 0.01)).To(Equal(big.NewRat(22, 7)))
+			Expect(Rationalize(math.Pi, 1e-6)).To(Equal(big.NewRat(355, 113)))
+			Expect(Rationalize(0.3, 0.5)).To(Equal(new(big.Rat)))
+			Expect(Rationalize(0.1, 0)).To(Equal(new(big.Rat).SetFloat64(0.1)))
+			Ω(func() { Rationalize(math.Inf(1)) }).Should(Panic())
+			Ω(func() { Rationalize(1.5, -1) }).Should(Panic())
+		})
+	})
+
+	Context("ContinuedFraction(x, n...) and FromContinuedFraction(terms)", func() {
+		It("expands and contracts continued fractions.", func() {
+			Expect(ContinuedFraction(big.NewRat(415, 93))).To(Equal([]*big.Int{big.NewInt(4), big.NewInt(2), big.NewInt(6), big.NewInt(7)}))
+			Expect(ContinuedFraction(math.Pi, 4)).To(Equal([]int{3, 7, 15, 1}))
+			Expect(ContinuedFraction(-0.5)).To(Equal([]int{-1, 2}))
+			Expect(FromContinuedFraction([]int{4, 2, 6, 7})).To(Equal(big.NewRat(415, 93)))
+			Expect(FromContinuedFraction(ContinuedFraction(math.Pi, 4))).To(Equal(big.NewRat(355, 113)))
+		})
+	})
+
+	Context("Plus, Subtract, Times and Divide", func() {
+		It("are exact for rationals.", func() {
+			third := big.NewRat(1, 3)
+			Expect(Plus(third, big.NewRat(1, 6))).To(Equal(big.NewRat(1, 2)))
+			Expect(Plus(third, 1)).To(Equal(big.NewRat(4, 3)))
+			Expect(Subtract(2, third)).To(Equal(big.NewRat(5, 3)))
+			Expect(Times(third, big.NewInt(3))).To(Equal(big.NewRat(1, 1)))
+			Expect(Divide(third, 2)).To(Equal(big.NewRat(1, 6)))
+			Expect(third).To(Equal(big.NewRat(1, 3)))
+		})
+
+		It("splits an amount without losing cents.", func() {
+			share := Divide(100, 3)
+			Expect(share).To(Equal(big.NewRat(100, 3)))
+			Expect(Plus(Plus(share, share), share)).To(Equal(big.NewRat(100, 1)))
+		})
+
+		It("keeps integers.", func() {
+			Expect(Plus(int8(100), int8(27))).To(Equal(int8(127)))
+			Expect(Plus(int8(100), int8(28))).To(Equal(big.NewInt(128)))
+			Expect(Times(3, big.NewInt(4))).To(Equal(big.NewInt(12)))
+			Expect(Divide(6, 3)).To(Equal(2))
+			Expect(Divide(6, 4)).To(Equal(big.NewRat(3, 2)))
+			Ω(func() { Divide(1, 0) }).Should(Panic())
+			Ω(func() { Plus(1, "a") }).Should(Panic())
+		})
+
+		It("compares, sorts and raises rationals exactly.", func() {
+			xs := []*big.Rat{big.NewRat(2, 3), big.NewRat(1, 2), big.NewRat(3, 5)}
+			Expect(Sort(xs)).To(Equal([]*big.Rat{big.NewRat(1, 2), big.NewRat(3, 5), big.NewRat(2, 3)}))
+			Expect(Max(xs)).To(Equal(big.NewRat(2, 3)))
+			Expect(Less(big.NewRat(1, 3), 0.5)).To(BeTrue())
+			Expect(Pow(big.NewRat(2, 3), 2)).To(Equal(big.NewRat(4, 9)))
+		})
+	})
+})
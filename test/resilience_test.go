@@ -0,0 +1,251 @@
+package test
+
+import (
+	"context"
+	"errors"
+	. "fp"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"math/rand"
+	"strconv"
+	"time"
+)
+
+var _ = Describe("resilience", func() {
+	var clock *fakeClock
+	flaky := errors.New("flaky")
+
+	BeforeEach(func() {
+		clock = &fakeClock{now: time.Unix(0, 0)}
+	})
+
+	// failing gives a function which fails n times before it succeeds.
+	failing := func(n int) (func(string) (int, error), *int) {
+		calls := 0
+		return func(s string) (int, error) {
+			calls++
+			if calls <= n {
+				return 0, flaky
+			}
+			return strconv.Atoi(s)
+		}, &calls
+	}
+
+	Context("Retry(f, policy)", func() {
+		It("retries with exponential backoff.", func() {
+			f, calls := failing(3)
+			g := Retry(f, RetryPolicy{MaxAttempts: 5, InitialDelay: time.Second, Clock: clock}).(func(string) (int, error))
+			n, err := g("7")
+			Expect(err).To(BeNil())
+			Expect(n).To(Equal(7))
+			Expect(*calls).To(Equal(4))
+			Expect(clock.Sleeps()).To(Equal([]time.Duration{time.Second, 2 * time.Second, 4 * time.Second}))
+		})
+
+		It("gives the last error after MaxAttempts.", func() {
+			f, calls := failing(10)
+			g := Retry(f, RetryPolicy{InitialDelay: time.Second, MaxDelay: 1500 * time.Millisecond, Clock: clock}).(func(string) (int, error))
+			_, err := g("7")
+			Expect(err).To(Equal(flaky))
+			Expect(*calls).To(Equal(3))
+			Expect(clock.Sleeps()).To(Equal([]time.Duration{time.Second, 1500 * time.Millisecond}))
+		})
+
+		It("retries functions giving other error interfaces.", func() {
+			type failure interface{ error }
+			f, calls := failing(1)
+			g := func(s string) (int, failure) { return f(s) }
+			h := Retry(g, RetryPolicy{InitialDelay: time.Second, Clock: clock}).(func(string) (int, failure))
+			Expect(h("7")).To(Equal(7))
+			Expect(*calls).To(Equal(2))
+		})
+
+		It("keeps jittered delays within the fraction.", func() {
+			f, _ := failing(10)
+			policy := RetryPolicy{MaxAttempts: 20, InitialDelay: time.Second, Multiplier: 1, Jitter: 0.5, Rand: rand.New(rand.NewSource(1)), Clock: clock}
+			Retry(f, policy).(func(string) (int, error))("1")
+			for _, d := range clock.Sleeps() {
+				Expect(d).To(BeNumerically(">=", 500*time.Millisecond))
+				Expect(d).To(BeNumerically("<=", 1500*time.Millisecond))
+			}
+		})
+
+		It("retries only errors accepted by RetryIf.", func() {
+			f, calls := failing(2)
+			policy := RetryPolicy{Clock: clock, RetryIf: func(err error) bool { return err != flaky }}
+			_, err := Retry(f, policy).(func(string) (int, error))("1")
+			Expect(err).To(Equal(flaky))
+			Expect(*calls).To(Equal(1))
+		})
+
+		It("stops waiting when ctx is done.", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			f := func(ctx context.Context) error {
+				cancel()
+				return flaky
+			}
+			err := Retry(f, RetryPolicy{InitialDelay: time.Hour, Clock: clock}).(func(context.Context) error)(ctx)
+			Expect(err).To(Equal(flaky))
+		})
+
+		It("works with Bind.", func() {
+			f, _ := failing(1)
+			g := Bind(Retry(f, RetryPolicy{Clock: clock}), func(n int) (int, error) { return n * 2, nil })
+			Expect(g("21")).To(Equal(42))
+		})
+
+		It("panics when f doesn't give an error.", func() {
+			Ω(func() { Retry(strconv.Itoa, RetryPolicy{}) }).Should(Panic())
+		})
+	})
+
+	Context("TimeConstrained(f, d, failexpr...)", func() {
+		slow := func(release chan struct{}) func(int) (int, error) {
+			return func(x int) (int, error) {
+				<-release
+				return x, nil
+			}
+		}
+
+		It("gives the result of f in time.", func() {
+			f := TimeConstrained(func(x int) int { return x * x }, time.Second, -1).(func(int) int)
+			Expect(f(3)).To(Equal(9))
+		})
+
+		It("gives ErrTimeout when f takes too long.", func() {
+			release := make(chan struct{})
+			defer close(release)
+			f := TimeConstrainedWithClock(slow(release), time.Second, clock).(func(int) (int, error))
+			done := make(chan error)
+			go func() {
+				_, err := f(1)
+				done <- err
+			}()
+			Eventually(clock.Timers).Should(Equal(1))
+			clock.Advance(time.Second)
+			Eventually(done).Should(Receive(Equal(ErrTimeout)))
+		})
+
+		It("gives failexpr when f takes too long.", func() {
+			release := make(chan struct{})
+			defer close(release)
+			block := func(x int) string {
+				<-release
+				return ""
+			}
+			f := TimeConstrainedWithClock(block, time.Second, clock, "$Aborted").(func(int) string)
+			done := make(chan string)
+			go func() { done <- f(1) }()
+			Eventually(clock.Timers).Should(Equal(1))
+			clock.Advance(time.Second)
+			Eventually(done).Should(Receive(Equal("$Aborted")))
+		})
+
+		It("cancels the ctx given to f.", func() {
+			cancelled := make(chan struct{})
+			f := func(ctx context.Context) error {
+				<-ctx.Done()
+				close(cancelled)
+				return ctx.Err()
+			}
+			g := TimeConstrainedWithClock(f, time.Second, clock).(func(context.Context) error)
+			done := make(chan error)
+			go func() { done <- g(context.Background()) }()
+			Eventually(clock.Timers).Should(Equal(1))
+			clock.Advance(time.Second)
+			Eventually(done).Should(Receive(Equal(ErrTimeout)))
+			Eventually(cancelled).Should(BeClosed())
+		})
+
+		It("panics when failexpr doesn't match the results.", func() {
+			Ω(func() { TimeConstrained(slow(nil), time.Second, "a", nil) }).Should(Panic())
+			Ω(func() { TimeConstrained(slow(nil), time.Second, 1) }).Should(Panic())
+		})
+
+		It("gives ErrTimeout in other error interfaces.", func() {
+			type failure interface{ error }
+			release := make(chan struct{})
+			defer close(release)
+			block := func(x int) (int, failure) {
+				<-release
+				return x, nil
+			}
+			f := TimeConstrainedWithClock(block, time.Second, clock).(func(int) (int, failure))
+			done := make(chan failure)
+			go func() {
+				_, err := f(1)
+				done <- err
+			}()
+			Eventually(clock.Timers).Should(Equal(1))
+			clock.Advance(time.Second)
+			Eventually(done).Should(Receive(Equal(ErrTimeout)))
+
+			type codedError interface {
+				error
+				Code() int
+			}
+			coded := func(x int) (int, codedError) { return x, nil }
+			Ω(func() { TimeConstrained(coded, time.Second) }).Should(Panic())
+			Expect(TimeConstrained(coded, time.Second, -1, nil).(func(int) (int, codedError))(2)).To(Equal(2))
+		})
+
+		It("panics without failexpr when f doesn't give error last.", func() {
+			Ω(func() { TimeConstrained(func(x int) int { return x }, time.Second) }).Should(Panic())
+			Ω(func() { TimeConstrained(func() {}, time.Second) }).Should(Panic())
+		})
+
+		It("treats a nil ctx as context.Background().", func() {
+			f := TimeConstrained(func(ctx context.Context) error { return ctx.Err() }, time.Second).(func(context.Context) error)
+			Expect(f(nil)).To(BeNil())
+		})
+	})
+
+	Context("CircuitBreaker(f, cfg)", func() {
+		It("opens after FailureThreshold failures and recovers after ResetTimeout.", func() {
+			f, calls := failing(3)
+			b := NewCircuitBreaker(f, BreakerConfig{FailureThreshold: 2, ResetTimeout: time.Minute, Clock: clock})
+			g := b.Func().(func(string) (int, error))
+
+			g("1")
+			g("1")
+			Expect(b.State()).To(Equal(Open))
+			_, err := g("1")
+			Expect(err).To(Equal(ErrCircuitOpen))
+			Expect(*calls).To(Equal(2))
+
+			clock.Advance(time.Minute)
+			Expect(b.State()).To(Equal(HalfOpen))
+			_, err = g("1")
+			Expect(err).To(Equal(flaky))
+			Expect(b.State()).To(Equal(Open))
+
+			clock.Advance(time.Minute)
+			Expect(g("5")).To(Equal(5))
+			Expect(b.State()).To(Equal(Closed))
+			Expect(*calls).To(Equal(4))
+		})
+
+		It("gives ErrCircuitOpen in other error interfaces.", func() {
+			type failure interface{ error }
+			f, _ := failing(5)
+			g := func(s string) (int, failure) { return f(s) }
+			h := CircuitBreaker(g, BreakerConfig{FailureThreshold: 1, Clock: clock}).(func(string) (int, failure))
+			h("1")
+			_, err := h("1")
+			Expect(err).To(Equal(ErrCircuitOpen))
+		})
+
+		It("ignores errors rejected by IsFailure.", func() {
+			f, _ := failing(5)
+			cfg := BreakerConfig{FailureThreshold: 1, Clock: clock, IsFailure: func(err error) bool { return err != flaky }}
+			g := CircuitBreaker(f, cfg).(func(string) (int, error))
+			g("1")
+			_, err := g("1")
+			Expect(err).To(Equal(flaky))
+		})
+
+		It("is described by its state.", func() {
+			Expect(HalfOpen.String()).To(Equal("HalfOpen"))
+		})
+	})
+})
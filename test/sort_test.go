@@ -1,9 +1,11 @@
 package test
 
 import (
+	"fmt"
 	. "fp"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"math"
 )
 
 var _ = Describe("list", func() {
@@ -83,4 +85,25 @@ var _ = Describe("list", func() {
 			Expect(actual).To(Equal(expected))
 		})
 	})
-})
\ No newline at end of file
+
+	Context("fast paths of Sort", func() {
+		It("give the same results as the reflective path and leave list alone.", func() {
+			xs := []int{3, 1, 2}
+			Expect(Sort(xs)).To(Equal([]int{1, 2, 3}))
+			Expect(Sort(xs, Greater)).To(Equal([]int{3, 2, 1}))
+			Expect(Sort([]string{"b", "c", "a"}, Greater)).To(Equal([]string{"c", "b", "a"}))
+			Expect(Sort([]float64{2.5, -1, 0})).To(Equal([]float64{-1, 0, 2.5}))
+			Expect(xs).To(Equal([]int{3, 1, 2}))
+		})
+
+		It("order lists with NaN like the reflective path.", func() {
+			less := func(a, b interface{}) bool { return Less(a, b) }
+			greater := func(a, b interface{}) bool { return Greater(a, b) }
+			nan := math.NaN()
+			for _, xs := range [][]float64{{3, nan, 1, nan, 2}, {nan, 1}, {1, nan}, {2, 1, nan, math.Inf(-1), 0}} {
+				Expect(fmt.Sprint(Sort(xs))).To(Equal(fmt.Sprint(Sort(xs, less))))
+				Expect(fmt.Sprint(Sort(xs, Greater))).To(Equal(fmt.Sprint(Sort(xs, greater))))
+			}
+		})
+	})
+})
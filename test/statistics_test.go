@@ -0,0 +1,174 @@
+package test
+
+import (
+	. "fp"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"math"
+	"math/big"
+)
+
+var _ = Describe("statistics", func() {
+	xs := []int{2, 4, 4, 4, 5, 5, 7, 9}
+
+	Context("Total(list)", func() {
+		It("keeps the element type.", func() {
+			Expect(Total(xs)).To(Equal(40))
+			Expect(Total([]int8{1, 2})).To(Equal(int8(3)))
+			Expect(Total([3]uint{1, 2, 3})).To(Equal(uint(6)))
+			Expect(Total([]complex128{1 + 1i, 2})).To(Equal(3 + 1i))
+			Expect(Total([]float64{})).To(Equal(0.0))
+		})
+
+		It("promotes overflows to big.Int.", func() {
+			Expect(Total([]int8{100, 100})).To(Equal(big.NewInt(200)))
+			Expect(Total([]int8{100, 100, -100})).To(Equal(int8(100)))
+			Expect(Total([]int64{math.MaxInt64, 1})).To(Equal(new(big.Int).Add(big.NewInt(math.MaxInt64), big.NewInt(1))))
+			Expect(Total([]uint64{math.MaxUint64, 1})).To(Equal(new(big.Int).Lsh(big.NewInt(1), 64)))
+		})
+
+		It("compensates rounding errors.", func() {
+			Expect(Total([]float64{1e100, 1, -1e100})).To(Equal(1.0))
+			ys := make([]float64, 10)
+			for i := range ys {
+				ys[i] = 0.1
+			}
+			Expect(Total(ys)).To(Equal(1.0))
+		})
+
+		It("panics on non-numbers.", func() {
+			Ω(func() { Total([]string{"a"}) }).Should(Panic())
+		})
+	})
+
+	Context("Mean(list) and Median(list)", func() {
+		It("gives floats.", func() {
+			Expect(Mean(xs)).To(Equal(5.0))
+			Expect(Mean([]float32{1, 2})).To(Equal(float32(1.5)))
+			Expect(Mean([]complex128{1 + 2i, 3})).To(Equal(2 + 1i))
+			Expect(Median(xs)).To(Equal(4.5))
+			Expect(Median([]int{3, 1, 2})).To(Equal(2.0))
+		})
+
+		It("panics on an empty list.", func() {
+			Ω(func() { Mean([]int{}) }).Should(Panic())
+			Ω(func() { Median([]float64{}) }).Should(Panic())
+		})
+	})
+
+	Context("Commonest(list, n...) and Mode(list)", func() {
+		It("gives the commonest elements.", func() {
+			Expect(Commonest(xs)).To(Equal([]int{4}))
+			Expect(Commonest([]string{"b", "a", "a", "b", "c"})).To(Equal([]string{"b", "a"}))
+			Expect(Commonest(xs, 2)).To(Equal([]int{4, 5}))
+			Expect(Commonest(xs, 100)).To(Equal([]int{4, 5, 2, 7, 9}))
+			Expect(Mode(xs)).To(Equal(4))
+		})
+	})
+
+	Context("Variance(list) and StandardDeviation(list)", func() {
+		It("gives the sample statistics.", func() {
+			Expect(Variance(xs)).To(BeNumerically("~", 32.0/7, 1e-12))
+			Expect(StandardDeviation(xs)).To(BeNumerically("~", math.Sqrt(32.0/7), 1e-12))
+		})
+
+		It("is stable with a large offset.", func() {
+			ys := []float64{1e9 + 4, 1e9 + 7, 1e9 + 13, 1e9 + 16}
+			Expect(Variance(ys)).To(Equal(30.0))
+		})
+
+		It("panics on fewer than 2 elements.", func() {
+			Ω(func() { Variance([]int{1}) }).Should(Panic())
+		})
+	})
+
+	Context("Quantile(list, q, parameters...)", func() {
+		It("gives elements by default.", func() {
+			Expect(Quantile(xs, 0.25)).To(Equal(4))
+			Expect(Quantile(xs, 0.3)).To(Equal(4))
+			Expect(Quantile(xs, 0.9)).To(Equal(9))
+			Expect(Quantile(xs, []float64{0, 1})).To(Equal([]int{2, 9}))
+		})
+
+		It("interpolates with other parameters.", func() {
+			Expect(Quantile(xs, 0.5, QuantileLinear)).To(Equal(4.5))
+			Expect(Quantile([]int{1, 2, 3, 4}, 0.25, QuantileLinear)).To(Equal(1.75))
+			Expect(Quantile([]int{1, 2, 3, 4}, 0.25, QuantileHazen)).To(Equal(1.5))
+			Expect(Quantile([]int{1, 2, 3, 4}, []float64{0.5}, QuantileWeibull)).To(Equal([]float64{2.5}))
+		})
+
+		It("panics when q is out of range.", func() {
+			Ω(func() { Quantile(xs, 1.5) }).Should(Panic())
+		})
+	})
+
+	Context("Skewness(list) and Kurtosis(list)", func() {
+		It("gives the coefficients of the central moments.", func() {
+			Expect(Skewness([]int{1, 2, 3})).To(BeNumerically("~", 0, 1e-12))
+			m2 := 438.0 / 27
+			m3 := 3570.0 / 81
+			Expect(Skewness([]int{1, 2, 10})).To(BeNumerically("~", m3/math.Pow(m2, 1.5), 1e-12))
+			Expect(Kurtosis([]int{1, 2, 3, 4})).To(BeNumerically("~", 1.64, 1e-12))
+		})
+	})
+
+	Context("Covariance(list1, list2) and Correlation(list1, list2)", func() {
+		It("gives the sample statistics.", func() {
+			Expect(Covariance([]int{1, 2, 3}, []float64{1, 2, 3.5})).To(BeNumerically("~", 1.25, 1e-12))
+			Expect(Correlation([]int{1, 2, 3}, []int{2, 4, 6})).To(BeNumerically("~", 1, 1e-12))
+			Expect(Correlation([]int{1, 2, 3}, []int{3, 2, 1})).To(BeNumerically("~", -1, 1e-12))
+		})
+
+		It("panics on lists of different lengths.", func() {
+			Ω(func() { Covariance([]int{1, 2}, []int{1, 2, 3}) }).Should(Panic())
+		})
+	})
+
+	Context("MovingAverage(list, window)", func() {
+		It("averages consecutive elements.", func() {
+			Expect(MovingAverage([]int{1, 2, 3, 4, 5}, 2)).To(Equal([]float64{1.5, 2.5, 3.5, 4.5}))
+			Expect(MovingAverage([]float32{1, 2, 3}, 3)).To(Equal([]float32{2}))
+		})
+
+		It("weights consecutive elements.", func() {
+			actual := MovingAverage([]int{1, 2, 3}, []float64{1, 2}).([]float64)
+			Expect(actual[0]).To(BeNumerically("~", 5.0/3, 1e-12))
+			Expect(actual[1]).To(BeNumerically("~", 8.0/3, 1e-12))
+		})
+
+		It("panics when the window is longer than the list.", func() {
+			Ω(func() { MovingAverage([]int{1, 2}, 3) }).Should(Panic())
+			Ω(func() { MovingAverage([]int{1, 2}, -1) }).Should(PanicWith("MovingAverage: the window -1 should be between 1 and 2."))
+			Ω(func() { MovingAverage([]int{1, 2}, 0) }).Should(Panic())
+		})
+	})
+
+	Context("Standardize(list)", func() {
+		It("gives mean 0 and standard deviation 1.", func() {
+			Expect(Standardize([]int{1, 2, 3})).To(Equal([]float64{-1, 0, 1}))
+		})
+	})
+
+	Context("Summary(list)", func() {
+		It("gives all the statistics.", func() {
+			s := Summary(xs)
+			Expect(s.Count).To(Equal(8))
+			Expect(s.Total).To(Equal(40))
+			Expect(s.Min).To(Equal(2))
+			Expect(s.Max).To(Equal(9))
+			Expect(s.Mean).To(Equal(5.0))
+			Expect(s.Median).To(Equal(4.5))
+			Expect(s.Mode).To(Equal(4))
+			Expect(s.Quartiles).To(Equal([3]float64{4, 4.5, 6}))
+			Expect(s.Variance).To(BeNumerically("~", 32.0/7, 1e-12))
+			Expect(s.Skewness).To(Equal(Skewness(xs)))
+			Expect(s.Kurtosis).To(Equal(Kurtosis(xs)))
+		})
+
+		It("gives NaN for the variance of one element.", func() {
+			s := Summary([]float64{2})
+			Expect(s.Mean).To(Equal(2.0))
+			Expect(math.IsNaN(s.Variance)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,154 @@
+package test
+
+import (
+	"context"
+	. "fp"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"strconv"
+	"time"
+)
+
+func source(xs ...int) <-chan int {
+	ch := make(chan int)
+	go func() {
+		defer close(ch)
+		for _, x := range xs {
+			ch <- x
+		}
+	}()
+	return ch
+}
+
+func drain(ch <-chan int) []int {
+	xs := []int{}
+	for x := range ch {
+		xs = append(xs, x)
+	}
+	return xs
+}
+
+var _ = Describe("stream", func() {
+	ctx := context.Background()
+
+	Context("MapChan(ctx, f, ch)", func() {
+		It("applies f to each element of ch.", func() {
+			out := MapChan(ctx, func(x int) string { return strconv.Itoa(x * 2) }, source(1, 2, 3)).(<-chan string)
+			actual := []string{}
+			for s := range out {
+				actual = append(actual, s)
+			}
+			Expect(actual).To(Equal([]string{"2", "4", "6"}))
+		})
+
+		It("panics when f doesn't accept the elements.", func() {
+			Ω(func() { MapChan(ctx, func(x string) string { return x }, source()) }).Should(Panic())
+		})
+
+		It("stops when ctx is cancelled.", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			in := make(chan int)
+			out := MapChan(ctx, func(x int) int { return x }, in).(<-chan int)
+			cancel()
+			Eventually(out).Should(BeClosed())
+		})
+	})
+
+	Context("FilterChan(ctx, f, ch)", func() {
+		It("picks out elements for which f is true.", func() {
+			out := FilterChan(ctx, func(x int) bool { return x%2 == 0 }, source(1, 2, 3, 4)).(<-chan int)
+			Expect(drain(out)).To(Equal([]int{2, 4}))
+		})
+	})
+
+	Context("FoldChan(ctx, f, x, ch)", func() {
+		It("combines elements of ch.", func() {
+			actual, err := FoldChan(ctx, func(r, x int) int { return r + x }, 0, source(1, 2, 3, 4))
+			Expect(err).To(BeNil())
+			Expect(actual).To(Equal(10))
+		})
+
+		It("gives the error of ctx when cancelled.", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, err := FoldChan(ctx, func(r, x int) int { return r + x }, 0, make(chan int))
+			Expect(err).To(Equal(context.Canceled))
+		})
+	})
+
+	Context("BatchChan(ctx, ch, size, window)", func() {
+		It("groups elements by size.", func() {
+			out := BatchChan(ctx, source(1, 2, 3, 4, 5), 2, 0).(<-chan []int)
+			actual := [][]int{}
+			for batch := range out {
+				actual = append(actual, batch)
+			}
+			Expect(actual).To(Equal([][]int{{1, 2}, {3, 4}, {5}}))
+		})
+
+		It("emits a partial batch when the window expires.", func() {
+			in := make(chan int)
+			out := BatchChan(ctx, in, 10, 20*time.Millisecond).(<-chan []int)
+			in <- 1
+			in <- 2
+			Eventually(out).Should(Receive(Equal([]int{1, 2})))
+			in <- 3
+			close(in)
+			Eventually(out).Should(Receive(Equal([]int{3})))
+			Eventually(out).Should(BeClosed())
+		})
+
+		It("panics on a negative size or window.", func() {
+			Ω(func() { BatchChan(ctx, source(1), -1, time.Second) }).Should(Panic())
+			Ω(func() { BatchChan(ctx, source(1), 2, -time.Second) }).Should(Panic())
+			Ω(func() { BatchChan(ctx, source(1), 0, 0) }).Should(Panic())
+		})
+	})
+
+	Context("MergeChans(ctx, chs...)", func() {
+		It("interleaves elements of all channels.", func() {
+			out := MergeChans(ctx, source(1, 2), source(3), source(4, 5)).(<-chan int)
+			Expect(Sort(drain(out))).To(Equal([]int{1, 2, 3, 4, 5}))
+		})
+
+		It("panics when element types are different.", func() {
+			Ω(func() { MergeChans(ctx, source(1), make(chan string)) }).Should(Panic())
+		})
+	})
+
+	Context("TeeChan(ctx, ch, n)", func() {
+		It("copies each element to n channels.", func() {
+			outs := TeeChan(ctx, source(1, 2, 3), 2).([]<-chan int)
+			Expect(outs).To(HaveLen(2))
+			second := make(chan []int)
+			go func() { second <- drain(outs[1]) }()
+			Expect(drain(outs[0])).To(Equal([]int{1, 2, 3}))
+			Expect(<-second).To(Equal([]int{1, 2, 3}))
+		})
+	})
+
+	Context("ParallelMapChan(ctx, f, ch, workers, order)", func() {
+		slowSquare := func(x int) int {
+			time.Sleep(time.Duration(10-x) * time.Millisecond)
+			return x * x
+		}
+
+		It("keeps the input order.", func() {
+			out := ParallelMapChan(ctx, slowSquare, source(Range(9)...), 4, Ordered).(<-chan int)
+			Expect(drain(out)).To(Equal([]int{1, 4, 9, 16, 25, 36, 49, 64, 81}))
+		})
+
+		It("emits results as they complete.", func() {
+			out := ParallelMapChan(ctx, slowSquare, source(Range(9)...), 4, Unordered).(<-chan int)
+			Expect(Sort(drain(out))).To(Equal([]int{1, 4, 9, 16, 25, 36, 49, 64, 81}))
+		})
+
+		It("stops when ctx is cancelled.", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			out := ParallelMapChan(ctx, slowSquare, source(Range(9)...), 2, Ordered).(<-chan int)
+			Expect(<-out).To(Equal(1))
+			cancel()
+			Eventually(out).Should(BeClosed())
+		})
+	})
+})